@@ -1,7 +1,12 @@
 package auth
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"net/http"
@@ -22,43 +27,158 @@ func ValidateHash(password string, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 }
 
-func MakeJWT(id uuid.UUID, secret string, expiration time.Duration) (string, error) {
+// ValidateDummyHash takes as long as ValidateHash and always fails, so that
+// logging in as somebody unknown cannot be told apart by its timing.
+func ValidateDummyHash(password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password)); err != nil {
+		return err
+	}
+	return bcrypt.ErrMismatchedHashAndPassword
+}
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Role         string `json:"role,omitempty"`
+	TokenVersion int32  `json:"ver,omitempty"`
+	SessionId    string `json:"sid,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	ClientId     string `json:"client_id,omitempty"`
+}
+
+func MakeJWT(id uuid.UUID, role string, secret string, expiration time.Duration) (string, error) {
+	return newToken(jwt.SigningMethodHS256, Claims{Role: role}, id, expiration).SignedString([]byte(secret))
+}
+
+func ValidateJWT(tokenString string, secret string) (uuid.UUID, error) {
+	if claims, err := ValidateJWTClaims(tokenString, secret); err != nil {
+		return uuid.Nil, err
+	} else {
+		return uuid.MustParse(claims.Subject), nil
+	}
+}
+
+func ValidateJWTClaims(tokenString string, secret string) (Claims, error) {
+	return validateJWTClaims(
+		tokenString,
+		func(token *jwt.Token) (any, error) {
+			return []byte(secret), nil
+		},
+		AlgorithmHs256,
+	)
+}
+
+func newToken(method jwt.SigningMethod, claims Claims, id uuid.UUID, expiration time.Duration) *jwt.Token {
 	start := jwt.NumericDate{Time: time.Now()}
 	end := jwt.NumericDate{Time: start.Add(expiration)}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+	claims.RegisteredClaims = jwt.RegisteredClaims{
 		Issuer:    jwtIssuer,
 		Subject:   id.String(),
 		ExpiresAt: &end,
 		IssuedAt:  &start,
-	})
-	return token.SignedString([]byte(secret))
+	}
+	return jwt.NewWithClaims(method, claims)
 }
 
-func ValidateJWT(tokenString string, secret string) (uuid.UUID, error) {
-	claims := jwt.RegisteredClaims{}
+func validateJWTClaims(tokenString string, keyFunc jwt.Keyfunc, algorithms ...string) (Claims, error) {
+	claims := Claims{}
 	if _, err := jwt.ParseWithClaims(
 		tokenString,
 		&claims,
-		func(token *jwt.Token) (any, error) {
-			return []byte(secret), nil
-		},
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		keyFunc,
+		jwt.WithValidMethods(algorithms),
 		jwt.WithIssuer(jwtIssuer),
 	); err != nil {
-		return uuid.Nil, err
-	} else if id, err := uuid.Parse(claims.Subject); err != nil {
-		return uuid.Nil, jwt.ErrTokenInvalidSubject
+		return Claims{}, err
+	} else if _, err := uuid.Parse(claims.Subject); err != nil {
+		return Claims{}, jwt.ErrTokenInvalidSubject
 	} else {
-		return id, nil
+		return claims, nil
 	}
 }
 
 func MakeRefreshToken() (string, error) {
-	refreshToken := make([]byte, refreshTokenLength)
-	if _, err := rand.Read(refreshToken); err != nil {
+	return MakeToken()
+}
+
+func MakeToken() (string, error) {
+	token := make([]byte, tokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return empty, err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// MakePersonalAccessToken prefixes the token so it can be told apart from a
+// JWT in the same Authorization header, and spotted by secret scanners.
+func MakePersonalAccessToken() (string, error) {
+	token, err := MakeToken()
+	if err != nil {
+		return empty, err
+	}
+	return personalAccessTokenPrefix + token, nil
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
+
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func MakeSignature(message string, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func ValidateSignature(message string, signature string, secret string) error {
+	if !hmac.Equal([]byte(MakeSignature(message, secret)), []byte(signature)) {
+		return errors.New(errorInvalidSignature)
+	}
+	return nil
+}
+
+func Encrypt(plaintext string, key []byte) (string, error) {
+	var err error
+	var block cipher.Block
+	var gcm cipher.AEAD
+	if block, err = aes.NewCipher(key); err != nil {
+		return empty, err
+	}
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return empty, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return empty, err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+func Decrypt(ciphertext string, key []byte) (string, error) {
+	var err error
+	var block cipher.Block
+	var gcm cipher.AEAD
+	var sealed, plaintext []byte
+	if sealed, err = base64.StdEncoding.DecodeString(ciphertext); err != nil {
+		return empty, err
+	}
+	if block, err = aes.NewCipher(key); err != nil {
+		return empty, err
+	}
+	if gcm, err = cipher.NewGCM(block); err != nil {
+		return empty, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return empty, errors.New(errorInvalidCiphertext)
+	}
+	nonce, sealed := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	if plaintext, err = gcm.Open(nil, nonce, sealed, nil); err != nil {
 		return empty, err
 	}
-	return hex.EncodeToString(refreshToken), nil
+	return string(plaintext), nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"testing"
 	"time"
@@ -59,6 +65,17 @@ func TestValidateHash(t *testing.T) {
 	}
 }
 
+func TestValidateDummyHash(t *testing.T) {
+	if !regexp.MustCompile(regexBcrypt).MatchString(dummyHash) {
+		t.Errorf("dummyHash = %q, want a bcrypt hash of the default cost", dummyHash)
+	}
+	for _, password := range []string{empty, "password", "04234"} {
+		if err := ValidateDummyHash(password); !errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			t.Errorf("ValidateDummyHash(%q) = %v, want %v", password, err, bcrypt.ErrMismatchedHashAndPassword)
+		}
+	}
+}
+
 func TestMakeJWT(t *testing.T) {
 	tests := []string{
 		"123456",
@@ -67,12 +84,12 @@ func TestMakeJWT(t *testing.T) {
 	}
 	want := regexp.MustCompile(regexJwt)
 	for _, inputSecret := range tests {
-		inputId, inputExpiry := uuid.New(), time.Minute
-		if output, err := MakeJWT(inputId, inputSecret, inputExpiry); err != nil ||
+		inputId, inputRole, inputExpiry := uuid.New(), "user", time.Minute
+		if output, err := MakeJWT(inputId, inputRole, inputSecret, inputExpiry); err != nil ||
 			!want.MatchString(output) {
 			t.Errorf(
-				"MakeJWT(%s, \"%s\", %.2fs) = (\"%s\", %v), want (%#q, nil)",
-				inputId, inputSecret, inputExpiry.Seconds(), output, err, want,
+				"MakeJWT(%s, \"%s\", \"%s\", %.2fs) = (\"%s\", %v), want (%#q, nil)",
+				inputId, inputRole, inputSecret, inputExpiry.Seconds(), output, err, want,
 			)
 		}
 	}
@@ -137,6 +154,401 @@ func TestValidateJWT(t *testing.T) {
 	}
 }
 
+func TestValidateJWTClaims(t *testing.T) {
+	tests, inputSecret := []string{"user", "moderator", "admin"}, "secret"
+	for _, inputRole := range tests {
+		inputId := uuid.New()
+		inputToken, _ := MakeJWT(inputId, inputRole, inputSecret, time.Minute)
+		if output, err := ValidateJWTClaims(inputToken, inputSecret); err != nil ||
+			output.Subject != inputId.String() || output.Role != inputRole {
+			t.Errorf(
+				"ValidateJWTClaims(\"%s\", \"%s\") = (%+v, %v), want (%s %s, nil)",
+				inputToken, inputSecret, output, err, inputId, inputRole,
+			)
+		}
+	}
+}
+
+func TestKeySet(t *testing.T) {
+	now := time.Now()
+	for _, algorithm := range []string{AlgorithmRs256, AlgorithmEdDsa} {
+		retiring, err := NewSigningKey(algorithm, now.Add(time.Hour), now.Add(2*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err := NewSigningKey(algorithm, now.Add(3*time.Hour), now.Add(4*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := NewKeySet(algorithm, "secret", true)
+		keys.SetKeys([]SigningKey{next, retiring})
+		inputId := uuid.New()
+		token, err := keys.MakeJWT(inputId, Claims{Role: "user"}, time.Minute)
+		if err != nil {
+			t.Fatalf("%s MakeJWT() error = %v", algorithm, err)
+		}
+		parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{})
+		if parsed.Method.Alg() != algorithm || parsed.Header[jwtHeaderKid] != retiring.Id {
+			t.Errorf("%s MakeJWT() header = %v, want kid %s", algorithm, parsed.Header, retiring.Id)
+		}
+		if output, err := keys.ValidateJWT(token); err != nil || output != inputId {
+			t.Errorf("%s ValidateJWT() = (%s, %v), want (%s, nil)", algorithm, output, err, inputId)
+		}
+		hs256, _ := MakeJWT(inputId, "user", "secret", time.Minute)
+		if _, err := keys.ValidateJWT(hs256); err != nil {
+			t.Errorf("%s ValidateJWT(HS256) error = %v", algorithm, err)
+		}
+		if _, err := NewKeySet(algorithm, "secret", false).ValidateJWT(hs256); err == nil {
+			t.Errorf("%s ValidateJWT(HS256) accepted after migration", algorithm)
+		}
+		keys.SetKeys([]SigningKey{next})
+		if _, err := keys.ValidateJWT(token); err == nil {
+			t.Errorf("%s ValidateJWT() accepted a dropped key", algorithm)
+		}
+		der, err := MarshalSigningKey(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsedKey, err := ParseSigningKey(algorithm, der, next.RetiresAt, next.ExpiresAt)
+		if err != nil || parsedKey.Id != next.Id {
+			t.Errorf("%s ParseSigningKey() = (%s, %v), want (%s, nil)", algorithm, parsedKey.Id, err, next.Id)
+		}
+		if jwks := keys.Jwks(); len(jwks.Keys) != 1 || jwks.Keys[0].Kid != next.Id ||
+			jwks.Keys[0].Alg != algorithm {
+			t.Errorf("%s Jwks() = %+v, want only %s", algorithm, jwks, next.Id)
+		}
+	}
+	if _, err := ParseSigningKey(AlgorithmEdDsa, nil, now, now); err == nil {
+		t.Error("ParseSigningKey(nil) expected error")
+	}
+	if _, err := NewSigningKey(AlgorithmHs256, now, now); err == nil {
+		t.Error("NewSigningKey(HS256) expected error")
+	}
+}
+
+func TestKeySetTokenVersions(t *testing.T) {
+	keys, version := NewKeySet(AlgorithmHs256, "secret", false), int32(2)
+	inputId, sessionId, sessionEnded := uuid.New(), uuid.New(), false
+	keys.SetTokenVersions(func(_ uuid.UUID, session string) (int32, error) {
+		if session != sessionId.String() || sessionEnded {
+			return 0, errors.New("session ended")
+		}
+		return version, nil
+	})
+	token, err := keys.MakeJWT(inputId, Claims{
+		Role:         "user",
+		TokenVersion: version,
+		SessionId:    sessionId.String(),
+	}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output, err := keys.ValidateJWTClaims(token); err != nil || output.TokenVersion != version ||
+		output.SessionId != sessionId.String() {
+		t.Errorf("ValidateJWTClaims() = (%+v, %v), want version %d and session %s",
+			output, err, version, sessionId)
+	}
+	version++
+	if _, err := keys.ValidateJWT(token); !errors.Is(err, jwt.ErrTokenInvalidClaims) {
+		t.Errorf("ValidateJWT() after version bump = %v, want %v", err, jwt.ErrTokenInvalidClaims)
+	}
+	version--
+	sessionEnded = true
+	if _, err := keys.ValidateJWT(token); !errors.Is(err, jwt.ErrTokenInvalidClaims) {
+		t.Errorf("ValidateJWT() after session end = %v, want %v", err, jwt.ErrTokenInvalidClaims)
+	}
+}
+
+// newMockOidcProvider serves discovery, keys and a token endpoint that
+// answers the code "code" with an ID token holding whatever claims returns.
+func newMockOidcProvider(t *testing.T, key SigningKey, claims func(issuer string) OidcClaims) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	keys := NewKeySet(key.Algorithm, empty, false)
+	keys.SetKeys([]SigningKey{key})
+	writeJson := func(w http.ResponseWriter, v any) {
+		w.Header().Set(headerContentType, "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			t.Error(err)
+		}
+	}
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, oidcMetadata{
+			Issuer:                server.URL,
+			AuthorizationEndpoint: server.URL + "/authorize",
+			TokenEndpoint:         server.URL + "/token",
+			JwksUri:               server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJson(w, keys.Jwks())
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		clientId, clientSecret, _ := r.BasicAuth()
+		if clientId != "chirpy" || clientSecret != "secret" || r.PostFormValue("code") != "code" ||
+			r.PostFormValue("code_verifier") != "verifier" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims(server.URL))
+		token.Header[jwtHeaderKid] = key.Id
+		idToken, err := token.SignedString(key.Private)
+		if err != nil {
+			t.Error(err)
+		}
+		writeJson(w, map[string]string{"id_token": idToken})
+	})
+	return server
+}
+
+func TestOidcProvider(t *testing.T) {
+	now := time.Now()
+	key, err := NewSigningKey(AlgorithmRs256, now.Add(time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := func(issuer string) OidcClaims {
+		return OidcClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    issuer,
+				Subject:   "employee-42",
+				Audience:  jwt.ClaimStrings{"chirpy"},
+				IssuedAt:  jwt.NewNumericDate(now),
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			},
+			Nonce:         "nonce",
+			Email:         "employee@example.com",
+			EmailVerified: true,
+		}
+	}
+	tests := map[string]struct {
+		claims func(issuer string) OidcClaims
+		nonce  string
+		valid  bool
+	}{
+		"valid":       {valid, "nonce", true},
+		"wrong nonce": {valid, "other", false},
+		"wrong audience": {func(issuer string) OidcClaims {
+			claims := valid(issuer)
+			claims.Audience = jwt.ClaimStrings{"someone-else"}
+			return claims
+		}, "nonce", false},
+		"wrong issuer": {func(issuer string) OidcClaims {
+			return valid("https://idp.example")
+		}, "nonce", false},
+		"expired": {func(issuer string) OidcClaims {
+			claims := valid(issuer)
+			claims.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Hour))
+			return claims
+		}, "nonce", false},
+		"other authorized party": {func(issuer string) OidcClaims {
+			claims := valid(issuer)
+			claims.Audience = jwt.ClaimStrings{"chirpy", "someone-else"}
+			claims.AuthorizedParty = "someone-else"
+			return claims
+		}, "nonce", false},
+	}
+	for name, test := range tests {
+		server := newMockOidcProvider(t, key, test.claims)
+		provider := NewOidcProvider(server.URL, "chirpy", "secret", "http://localhost:8080/callback")
+		claims, err := provider.Exchange(context.Background(), "code", "verifier", test.nonce)
+		if test.valid && (err != nil || claims.Subject != "employee-42" || !claims.EmailVerified) {
+			t.Errorf("%s: Exchange() = (%+v, %v), want employee-42", name, claims, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: Exchange() expected error", name)
+		}
+	}
+
+	server := newMockOidcProvider(t, key, valid)
+	provider := NewOidcProvider(server.URL, "chirpy", "secret", "http://localhost:8080/callback")
+	authCodeUrl, err := provider.AuthCodeUrl(context.Background(), "state", "nonce", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := url.Parse(authCodeUrl)
+	challenge := sha256.Sum256([]byte("verifier"))
+	if query := parsed.Query(); parsed.Path != "/authorize" || query.Get("state") != "state" ||
+		query.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(challenge[:]) ||
+		query.Get("code_challenge_method") != "S256" || query.Get("redirect_uri") != provider.RedirectUri {
+		t.Errorf("AuthCodeUrl() = %s", authCodeUrl)
+	}
+	if _, err := provider.Exchange(context.Background(), "stolen", "verifier", "nonce"); err == nil {
+		t.Error("Exchange() accepted a code the provider refused")
+	}
+	rogue, err := NewSigningKey(AlgorithmRs256, now.Add(time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, valid(server.URL))
+	token.Header[jwtHeaderKid] = rogue.Id
+	idToken, _ := token.SignedString(rogue.Private)
+	if _, err := provider.ValidateIdToken(context.Background(), idToken, "nonce"); err == nil {
+		t.Error("ValidateIdToken() accepted a key the provider never published")
+	}
+
+	misnamed := NewOidcProvider(server.URL+"/", "chirpy", "secret", "http://localhost:8080/callback")
+	if _, err := misnamed.AuthCodeUrl(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Error("AuthCodeUrl() accepted metadata for another issuer")
+	}
+}
+
+func TestMakeToken(t *testing.T) {
+	want := regexp.MustCompile(regexToken)
+	seen := map[string]bool{}
+	for range 3 {
+		output, err := MakeToken()
+		if err != nil || !want.MatchString(output) || seen[output] {
+			t.Errorf("MakeToken() = (\"%s\", %v), want (%#q, nil)", output, err, want)
+		}
+		seen[output] = true
+	}
+}
+
+func TestMakePersonalAccessToken(t *testing.T) {
+	want := regexp.MustCompile(regexPersonalAccessToken)
+	output, err := MakePersonalAccessToken()
+	if err != nil || !want.MatchString(output) {
+		t.Errorf("MakePersonalAccessToken() = (\"%s\", %v), want (%#q, nil)", output, err, want)
+	}
+	if !IsPersonalAccessToken(output) {
+		t.Errorf("IsPersonalAccessToken(\"%s\") = false, want true", output)
+	}
+	if jwt, _ := MakeJWT(uuid.New(), "user", "secret", time.Minute); IsPersonalAccessToken(jwt) {
+		t.Errorf("IsPersonalAccessToken(\"%s\") = true, want false", jwt)
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	tests := map[string]string{
+		"":       "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"qwerty": "65e84be33532fb784c48129675f9eff3a682b27168c0ea744b2cf58ee02337c5",
+	}
+	for input, want := range tests {
+		if output := HashToken(input); output != want {
+			t.Errorf("HashToken(\"%s\") = (\"%s\"), want (\"%s\")", input, output, want)
+		}
+	}
+}
+
+func TestMakeSignature(t *testing.T) {
+	tests := []string{
+		"123456",
+		"qwerty",
+		"secret",
+	}
+	want := regexp.MustCompile(regexSignature)
+	for _, inputMessage := range tests {
+		inputSecret := "secret"
+		if output := MakeSignature(inputMessage, inputSecret); !want.MatchString(output) {
+			t.Errorf(
+				"MakeSignature(\"%s\", \"%s\") = (\"%s\"), want (%#q)",
+				inputMessage, inputSecret, output, want,
+			)
+		}
+	}
+}
+
+func TestValidateSignature(t *testing.T) {
+	inputMessage, inputSecret := "message", "secret"
+	signature := MakeSignature(inputMessage, inputSecret)
+	testsOk := map[string]string{
+		inputMessage: signature,
+	}
+	testsErr := map[string]string{
+		"error":      signature,
+		inputMessage: MakeSignature(inputMessage, "error"),
+		"":           empty,
+	}
+	for input, output := range testsOk {
+		if err := ValidateSignature(input, output, inputSecret); err != nil {
+			t.Errorf(
+				"ValidateSignature(\"%s\", \"%s\", \"%s\") = (%v), want (nil)",
+				input, output, inputSecret, err,
+			)
+		}
+	}
+	for input, output := range testsErr {
+		if err := ValidateSignature(input, output, inputSecret); err == nil {
+			t.Errorf(
+				"ValidateSignature(\"%s\", \"%s\", \"%s\") = (nil), want (error)",
+				input, output, inputSecret,
+			)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	key, otherKey := make([]byte, 32), make([]byte, 32)
+	otherKey[0] = 1
+	for _, input := range []string{"", "JBSWY3DPEHPK3PXP", "secret"} {
+		ciphertext, err := Encrypt(input, key)
+		if err != nil {
+			t.Fatalf("Encrypt(\"%s\") = (%v), want (nil)", input, err)
+		}
+		if output, err := Decrypt(ciphertext, key); err != nil || output != input {
+			t.Errorf("Decrypt(Encrypt(\"%s\")) = (\"%s\", %v), want (\"%s\", nil)", input, output, err, input)
+		}
+		if _, err := Decrypt(ciphertext, otherKey); err == nil {
+			t.Errorf("Decrypt(\"%s\") with wrong key = (nil), want (error)", ciphertext)
+		}
+	}
+	for _, input := range []string{"", "not base64!", "AAAA"} {
+		if _, err := Decrypt(input, key); err == nil {
+			t.Errorf("Decrypt(\"%s\") = (nil), want (error)", input)
+		}
+	}
+}
+
+func TestMakeTotpCode(t *testing.T) {
+	// RFC 6238 appendix B, SHA1, truncated to six digits
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for input, want := range tests {
+		if output, err := MakeTotpCode(secret, time.Unix(input, 0)); err != nil || output != want {
+			t.Errorf("MakeTotpCode(\"%s\", %d) = (\"%s\", %v), want (\"%s\", nil)", secret, input, output, err, want)
+		}
+	}
+}
+
+func TestValidateTotpCode(t *testing.T) {
+	secret, err := MakeTotpSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, offset := range []time.Duration{-totpPeriod, 0, totpPeriod} {
+		code, _ := MakeTotpCode(secret, now.Add(offset))
+		if step, err := ValidateTotpCode(secret, code, now); err != nil || step != now.Add(offset).Unix()/30 {
+			t.Errorf("ValidateTotpCode(\"%s\", \"%s\") = (%d, %v), want (step, nil)", secret, code, step, err)
+		}
+	}
+	for _, offset := range []time.Duration{-3 * totpPeriod, 3 * totpPeriod} {
+		code, _ := MakeTotpCode(secret, now.Add(offset))
+		if _, err := ValidateTotpCode(secret, code, now); err == nil {
+			t.Errorf("ValidateTotpCode(\"%s\", \"%s\") = (nil), want (error)", secret, code)
+		}
+	}
+	if _, err := ValidateTotpCode(secret, "", now); err == nil {
+		t.Error("ValidateTotpCode() with empty code = (nil), want (error)")
+	}
+}
+
+func TestMakeTotpUri(t *testing.T) {
+	output := MakeTotpUri("Chirpy", "someone@example.com", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/Chirpy:someone@example.com?algorithm=SHA1&digits=6&issuer=Chirpy&period=30&secret=JBSWY3DPEHPK3PXP"
+	if output != want {
+		t.Errorf("MakeTotpUri() = (\"%s\"), want (\"%s\")", output, want)
+	}
+}
+
 func TestGetBearerToken(t *testing.T) {
 	testsOk := []http.Header{
 		{headerAuthorization: []string{authorizationBearer + " 123456"}},
@@ -1,19 +1,61 @@
 package auth
 
+import "time"
+
 const (
-	refreshTokenLength = 32
+	AlgorithmEdDsa = "EdDSA"
+	AlgorithmEs256 = "ES256"
+	AlgorithmHs256 = "HS256"
+	AlgorithmRs256 = "RS256"
+
+	kidLength        = 16
+	oidcKeysRefresh  = time.Minute
+	oidcLeeway       = time.Minute
+	oidcMaxBytes     = 1024 * 1024
+	oidcTimeout      = 10 * time.Second
+	rsaKeyBits       = 2048
+	tokenLength      = 32
+	totpDigits       = 6
+	totpModulus      = 1000000
+	totpPeriod       = 30 * time.Second
+	totpSecretLength = 20
 
-	authorizationApiKey    = "ApiKey"
-	authorizationBearer    = "Bearer"
-	empty                  = ""
-	errorInvalidAuthApiKey = "invalid Authorization ApiKey header"
-	errorInvalidAuthBearer = "invalid Authorization Bearer header"
-	errorMissingAuthApiKey = "missing Authorization ApiKey header"
-	errorMissingAuthBearer = "missing Authorization Bearer header"
-	headerAuthorization    = "Authorization"
-	jwtIssuer              = "chirpy"
-	space                  = " "
+	authorizationApiKey       = "ApiKey"
+	authorizationBearer       = "Bearer"
+	contentTypeForm           = "application/x-www-form-urlencoded"
+	dummyHash                 = "$2a$10$q42GOJG3Vmcdhl2.fJE6dOcuB8XYMBm/gLkVVN.FstowlNv.C8MAa"
+	empty                     = ""
+	errorInvalidAuthApiKey    = "invalid Authorization ApiKey header"
+	errorInvalidAuthBearer    = "invalid Authorization Bearer header"
+	errorInvalidCiphertext    = "invalid ciphertext"
+	errorInvalidNonce         = "invalid nonce"
+	errorInvalidOidcMetadata  = "invalid openid provider metadata"
+	errorInvalidSignature     = "invalid signature"
+	errorInvalidTotpCode      = "invalid totp code"
+	errorMissingAuthApiKey    = "missing Authorization ApiKey header"
+	errorMissingAuthBearer    = "missing Authorization Bearer header"
+	errorMissingSigningKey    = "missing signing key"
+	errorOidcStatus           = "%s answered with status %d"
+	errorUnknownSigningKey    = "unknown signing key"
+	errorUnsupportedAlgorithm = "unsupported signing algorithm"
+	headerAuthorization       = "Authorization"
+	headerContentType         = "Content-Type"
+	jwkCrvEd25519             = "Ed25519"
+	jwkCrvP256                = "P-256"
+	jwkKtyEc                  = "EC"
+	jwkKtyOkp                 = "OKP"
+	jwkKtyRsa                 = "RSA"
+	jwkUseSig                 = "sig"
+	jwtHeaderKid              = "kid"
+	jwtIssuer                 = "chirpy"
+	oidcDiscoveryPath         = "/.well-known/openid-configuration"
+	oidcScopes                = "openid email"
+	personalAccessTokenPrefix = "chirpy_pat_"
+	space                     = " "
 
-	regexBcrypt = `^\$2a\$10\$[./0-9A-Za-z]{53}$`
-	regexJwt    = `^(eyJ[-_0-9A-Za-z]+\.){2}[-_0-9A-Za-z]+$`
+	regexBcrypt              = `^\$2a\$10\$[./0-9A-Za-z]{53}$`
+	regexJwt                 = `^(eyJ[-_0-9A-Za-z]+\.){2}[-_0-9A-Za-z]+$`
+	regexPersonalAccessToken = `^chirpy_pat_[0-9a-f]{64}$`
+	regexSignature           = `^[0-9a-f]{64}$`
+	regexToken               = `^[0-9a-f]{64}$`
 )
@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// SigningKey signs new tokens until RetiresAt and keeps validating them, and
+// stays published in the JWKS, until ExpiresAt.
+type SigningKey struct {
+	Id        string
+	Algorithm string
+	Private   crypto.Signer
+	RetiresAt time.Time
+	ExpiresAt time.Time
+}
+
+// KeySet holds every signing key still in use. Tokens are signed with the
+// live key that retires first, so a freshly rotated key is published before
+// anything is signed with it. HS256 tokens made with the shared secret are
+// only accepted while acceptHs256 is set, which deployments moving off HS256
+// need for no longer than the tokens it signed take to expire.
+// Tokens whose version no longer matches the user's are rejected, so bumping
+// it logs every device out at once, and so are tokens whose session ended,
+// which versions reports as an error.
+type KeySet struct {
+	mutex       sync.RWMutex
+	keys        []SigningKey
+	algorithm   string
+	secret      string
+	acceptHs256 bool
+	versions    func(uuid.UUID, string) (int32, error)
+}
+
+type Jwks struct {
+	Keys []Jwk `json:"keys"`
+}
+
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+func NewKeySet(algorithm string, secret string, acceptHs256 bool) *KeySet {
+	return &KeySet{
+		algorithm:   algorithm,
+		secret:      secret,
+		acceptHs256: acceptHs256 || algorithm == AlgorithmHs256,
+	}
+}
+
+func (k *KeySet) SetKeys(keys []SigningKey) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.keys = keys
+}
+
+func (k *KeySet) SetTokenVersions(versions func(uuid.UUID, string) (int32, error)) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	k.versions = versions
+}
+
+func (k *KeySet) Algorithm() string {
+	return k.algorithm
+}
+
+// MakeJWT signs claims for the user id; the registered claims are filled in.
+func (k *KeySet) MakeJWT(id uuid.UUID, claims Claims, expiration time.Duration) (string, error) {
+	if k.algorithm == AlgorithmHs256 {
+		return newToken(jwt.SigningMethodHS256, claims, id, expiration).SignedString([]byte(k.secret))
+	}
+	key, ok := k.signingKey(time.Now())
+	if !ok {
+		return empty, errors.New(errorMissingSigningKey)
+	}
+	token := newToken(signingMethod(key.Algorithm), claims, id, expiration)
+	token.Header[jwtHeaderKid] = key.Id
+	return token.SignedString(key.Private)
+}
+
+func (k *KeySet) ValidateJWT(tokenString string) (uuid.UUID, error) {
+	if claims, err := k.ValidateJWTClaims(tokenString); err != nil {
+		return uuid.Nil, err
+	} else {
+		return uuid.MustParse(claims.Subject), nil
+	}
+}
+
+func (k *KeySet) ValidateJWTClaims(tokenString string) (Claims, error) {
+	claims, err := k.validateJWTClaims(tokenString)
+	if err != nil {
+		return Claims{}, err
+	}
+	k.mutex.RLock()
+	versions := k.versions
+	k.mutex.RUnlock()
+	if versions != nil {
+		if version, err := versions(uuid.MustParse(claims.Subject), claims.SessionId); err != nil ||
+			version != claims.TokenVersion {
+			return Claims{}, jwt.ErrTokenInvalidClaims
+		}
+	}
+	return claims, nil
+}
+
+func (k *KeySet) validateJWTClaims(tokenString string) (Claims, error) {
+	return validateJWTClaims(
+		tokenString,
+		func(token *jwt.Token) (any, error) {
+			if token.Method.Alg() == AlgorithmHs256 {
+				if !k.acceptHs256 || len(k.secret) == 0 {
+					return nil, errors.New(errorUnknownSigningKey)
+				}
+				return []byte(k.secret), nil
+			}
+			kid, _ := token.Header[jwtHeaderKid].(string)
+			key, ok := k.verificationKey(kid, time.Now())
+			if !ok || key.Algorithm != token.Method.Alg() {
+				return nil, errors.New(errorUnknownSigningKey)
+			}
+			return key.Private.Public(), nil
+		},
+		AlgorithmHs256, AlgorithmRs256, AlgorithmEdDsa,
+	)
+}
+
+// Jwks lists the public half of every key that may still sign or validate,
+// for services that check Chirpy tokens without knowing any secret.
+func (k *KeySet) Jwks() Jwks {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	jwks := Jwks{Keys: []Jwk{}}
+	now := time.Now()
+	for _, key := range k.keys {
+		if !now.Before(key.ExpiresAt) {
+			continue
+		}
+		jwk := Jwk{Use: jwkUseSig, Alg: key.Algorithm, Kid: key.Id}
+		switch public := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = jwkKtyRsa
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = jwkKtyOkp
+			jwk.Crv = jwkCrvEd25519
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (k *KeySet) signingKey(now time.Time) (SigningKey, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	var signing SigningKey
+	found := false
+	for _, key := range k.keys {
+		if key.Algorithm != k.algorithm || !now.Before(key.RetiresAt) {
+			continue
+		}
+		if !found || key.RetiresAt.Before(signing.RetiresAt) {
+			signing, found = key, true
+		}
+	}
+	return signing, found
+}
+
+func (k *KeySet) verificationKey(kid string, now time.Time) (SigningKey, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+	for _, key := range k.keys {
+		if key.Id == kid && now.Before(key.ExpiresAt) {
+			return key, true
+		}
+	}
+	return SigningKey{}, false
+}
+
+func NewSigningKey(algorithm string, retiresAt time.Time, expiresAt time.Time) (SigningKey, error) {
+	var err error
+	var private crypto.Signer
+	switch algorithm {
+	case AlgorithmRs256:
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	case AlgorithmEdDsa:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return SigningKey{}, errors.New(errorUnsupportedAlgorithm)
+	}
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return newSigningKey(algorithm, private, retiresAt, expiresAt)
+}
+
+func MarshalSigningKey(key SigningKey) ([]byte, error) {
+	return x509.MarshalPKCS8PrivateKey(key.Private)
+}
+
+func ParseSigningKey(algorithm string, der []byte, retiresAt time.Time,
+	expiresAt time.Time) (SigningKey, error) {
+	var err error
+	var private any
+	if private, err = x509.ParsePKCS8PrivateKey(der); err != nil {
+		return SigningKey{}, err
+	}
+	switch private.(type) {
+	case *rsa.PrivateKey:
+		if algorithm != AlgorithmRs256 {
+			return SigningKey{}, errors.New(errorUnsupportedAlgorithm)
+		}
+	case ed25519.PrivateKey:
+		if algorithm != AlgorithmEdDsa {
+			return SigningKey{}, errors.New(errorUnsupportedAlgorithm)
+		}
+	default:
+		return SigningKey{}, errors.New(errorUnsupportedAlgorithm)
+	}
+	return newSigningKey(algorithm, private.(crypto.Signer), retiresAt, expiresAt)
+}
+
+// newSigningKey names the key after a hash of its public half, so the same
+// key always gets the same kid.
+func newSigningKey(algorithm string, private crypto.Signer, retiresAt time.Time,
+	expiresAt time.Time) (SigningKey, error) {
+	der, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return SigningKey{}, err
+	}
+	return SigningKey{
+		Id:        HashToken(string(der))[:kidLength],
+		Algorithm: algorithm,
+		Private:   private,
+		RetiresAt: retiresAt,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case AlgorithmRs256:
+		return jwt.SigningMethodRS256
+	case AlgorithmEdDsa:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
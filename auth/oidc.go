@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OidcProvider signs users in through an external OpenID Connect identity
+// provider. Its endpoints are discovered on first use, and its keys fetched
+// again whenever an ID token names one not seen yet.
+type OidcProvider struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectUri  string
+	client       *http.Client
+	mutex        sync.Mutex
+	metadata     *oidcMetadata
+	keys         map[string]crypto.PublicKey
+	keysFetched  time.Time
+}
+
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// OidcClaims are the ID token claims Chirpy needs to find or link a user.
+type OidcClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	AuthorizedParty string `json:"azp"`
+}
+
+func NewOidcProvider(issuer string, clientId string, clientSecret string, redirectUri string) *OidcProvider {
+	return &OidcProvider{
+		Issuer:       issuer,
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+		RedirectUri:  redirectUri,
+		client:       &http.Client{Timeout: oidcTimeout},
+	}
+}
+
+// AuthCodeUrl is where to send the user to sign in with the provider, using
+// the authorization code flow with an S256 PKCE challenge.
+func (p *OidcProvider) AuthCodeUrl(ctx context.Context, state string, nonce string,
+	verifier string) (string, error) {
+	metadata, err := p.discover(ctx)
+	if err != nil {
+		return empty, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientId},
+		"redirect_uri":          {p.RedirectUri},
+		"scope":                 {oidcScopes},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems an authorization code and returns the claims of the ID
+// token that came with it, once its signature, issuer, audience, expiry and
+// nonce all check out.
+func (p *OidcProvider) Exchange(ctx context.Context, code string, verifier string,
+	nonce string) (OidcClaims, error) {
+	var err error
+	var metadata oidcMetadata
+	var request *http.Request
+	var response *http.Response
+	if metadata, err = p.discover(ctx); err != nil {
+		return OidcClaims{}, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectUri},
+		"code_verifier": {verifier},
+	}
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		metadata.TokenEndpoint,
+		strings.NewReader(form.Encode()),
+	); err != nil {
+		return OidcClaims{}, err
+	}
+	request.Header.Set(headerContentType, contentTypeForm)
+	request.SetBasicAuth(url.QueryEscape(p.ClientId), url.QueryEscape(p.ClientSecret))
+	if response, err = p.client.Do(request); err != nil {
+		return OidcClaims{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return OidcClaims{}, fmt.Errorf(errorOidcStatus, metadata.TokenEndpoint, response.StatusCode)
+	}
+	tokens := struct {
+		IdToken string `json:"id_token"`
+	}{}
+	if err = json.NewDecoder(io.LimitReader(response.Body, oidcMaxBytes)).Decode(&tokens); err != nil {
+		return OidcClaims{}, err
+	}
+	return p.ValidateIdToken(ctx, tokens.IdToken, nonce)
+}
+
+func (p *OidcProvider) ValidateIdToken(ctx context.Context, idToken string, nonce string) (OidcClaims, error) {
+	var err error
+	var metadata oidcMetadata
+	var token *jwt.Token
+	if metadata, err = p.discover(ctx); err != nil {
+		return OidcClaims{}, err
+	}
+	claims := OidcClaims{}
+	if token, err = jwt.ParseWithClaims(
+		idToken,
+		&claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header[jwtHeaderKid].(string)
+			return p.publicKey(ctx, metadata, kid)
+		},
+		jwt.WithValidMethods([]string{AlgorithmRs256, AlgorithmEs256, AlgorithmEdDsa}),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(oidcLeeway),
+	); err != nil {
+		return OidcClaims{}, err
+	}
+	if !token.Valid || len(claims.Subject) == 0 {
+		return OidcClaims{}, jwt.ErrTokenInvalidClaims
+	}
+	if (len(claims.Audience) > 1 || len(claims.AuthorizedParty) > 0) && claims.AuthorizedParty != p.ClientId {
+		return OidcClaims{}, jwt.ErrTokenInvalidAudience
+	}
+	if len(nonce) == 0 || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return OidcClaims{}, errors.New(errorInvalidNonce)
+	}
+	return claims, nil
+}
+
+// discover fetches the provider metadata once, and refuses it unless it
+// names the configured issuer.
+func (p *OidcProvider) discover(ctx context.Context) (oidcMetadata, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.metadata != nil {
+		return *p.metadata, nil
+	}
+	metadata := oidcMetadata{}
+	if err := p.getJson(ctx, strings.TrimSuffix(p.Issuer, "/")+oidcDiscoveryPath, &metadata); err != nil {
+		return metadata, err
+	}
+	if metadata.Issuer != p.Issuer || len(metadata.AuthorizationEndpoint) == 0 ||
+		len(metadata.TokenEndpoint) == 0 || len(metadata.JwksUri) == 0 {
+		return metadata, errors.New(errorInvalidOidcMetadata)
+	}
+	p.metadata = &metadata
+	return metadata, nil
+}
+
+// publicKey looks a key up by kid, fetching the provider keys again when it
+// is unknown, but not more than once per oidcKeysRefresh.
+func (p *OidcProvider) publicKey(ctx context.Context, metadata oidcMetadata, kid string) (crypto.PublicKey, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetched) < oidcKeysRefresh {
+		return nil, errors.New(errorUnknownSigningKey)
+	}
+	jwks := Jwks{}
+	if err := p.getJson(ctx, metadata.JwksUri, &jwks); err != nil {
+		return nil, err
+	}
+	p.keys = map[string]crypto.PublicKey{}
+	p.keysFetched = time.Now()
+	for _, jwk := range jwks.Keys {
+		if len(jwk.Use) > 0 && jwk.Use != jwkUseSig {
+			continue
+		}
+		if key, err := publicKeyFromJwk(jwk); err == nil {
+			p.keys[jwk.Kid] = key
+		}
+	}
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errors.New(errorUnknownSigningKey)
+}
+
+func (p *OidcProvider) getJson(ctx context.Context, uri string, v any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	response, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf(errorOidcStatus, uri, response.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(response.Body, oidcMaxBytes)).Decode(v)
+}
+
+func publicKeyFromJwk(jwk Jwk) (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch jwk.Kty {
+	case jwkKtyRsa:
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New(errorUnsupportedAlgorithm)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case jwkKtyEc:
+		if jwk.Crv != jwkCrvP256 {
+			return nil, errors.New(errorUnsupportedAlgorithm)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err = key.ECDH(); err != nil {
+			return nil, err
+		}
+		return key, nil
+	case jwkKtyOkp:
+		x, err := decode(jwk.X)
+		if jwk.Crv != jwkCrvEd25519 || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New(errorUnsupportedAlgorithm)
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.New(errorUnsupportedAlgorithm)
+	}
+}
@@ -12,24 +12,36 @@ import (
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, status, fingerprint, spam_score)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, status, fingerprint, spam_score
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.NullUUID
+	Body        string
+	UserID      uuid.NullUUID
+	Status      string
+	Fingerprint string
+	SpamScore   float64
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.Status,
+		arg.Fingerprint,
+		arg.SpamScore,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +49,9 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Status,
+		&i.Fingerprint,
+		&i.SpamScore,
 	)
 	return i, err
 }
@@ -12,7 +12,7 @@ import (
 )
 
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, status, fingerprint, spam_score
 FROM chirps
 WHERE id = $1
 `
@@ -26,6 +26,9 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.Status,
+		&i.Fingerprint,
+		&i.SpamScore,
 	)
 	return i, err
 }
@@ -10,8 +10,13 @@ import (
 )
 
 const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, status, fingerprint, spam_score
 FROM chirps
+WHERE status = 'published' AND NOT EXISTS (
+    SELECT 1
+    FROM users
+    WHERE users.id = chirps.user_id AND (suspended_at IS NOT NULL OR deactivated_at IS NOT NULL)
+)
 ORDER BY created_at ASC
 `
 
@@ -30,6 +35,9 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Status,
+			&i.Fingerprint,
+			&i.SpamScore,
 		); err != nil {
 			return nil, err
 		}
@@ -12,9 +12,13 @@ import (
 )
 
 const getChirpsFromUser = `-- name: GetChirpsFromUser :many
-SELECT id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, status, fingerprint, spam_score
 FROM chirps
-WHERE user_id = $1
+WHERE user_id = $1 AND status = 'published' AND NOT EXISTS (
+    SELECT 1
+    FROM users
+    WHERE users.id = chirps.user_id AND (suspended_at IS NOT NULL OR deactivated_at IS NOT NULL)
+)
 ORDER BY created_at ASC
 `
 
@@ -33,6 +37,9 @@ func (q *Queries) GetChirpsFromUser(ctx context.Context, userID uuid.NullUUID) (
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.Status,
+			&i.Fingerprint,
+			&i.SpamScore,
 		); err != nil {
 			return nil, err
 		}
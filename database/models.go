@@ -11,28 +11,249 @@ import (
 	"github.com/google/uuid"
 )
 
+type Avatar struct {
+	UserID      uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ContentType string
+	Image       []byte
+}
+
 type Chirp struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Body        string
+	UserID      uuid.NullUUID
+	Status      string
+	Fingerprint string
+	SpamScore   float64
+}
+
+type ChirpLink struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	ChirpID     uuid.UUID
+	Position    int32
+	Url         string
+	Code        string
+	Title       sql.NullString
+	Description sql.NullString
+	ImageUrl    sql.NullString
+	SiteName    sql.NullString
+	FetchedAt   sql.NullTime
+	FetchError  sql.NullString
+}
+
+type DataExport struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.NullUUID
+	UserID    uuid.UUID
+	Status    string
+	Archive   []byte
+	ExpiresAt time.Time
 }
 
-type RefreshToken struct {
-	Token     string
+type EmailVerification struct {
+	ID        uuid.UUID
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	UserID    uuid.NullUUID
+	UserID    uuid.UUID
+	Email     string
+	TokenHash string
 	ExpiresAt time.Time
-	RevokedAt sql.NullTime
+	UsedAt    sql.NullTime
+}
+
+type ErasureReceipt struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UserID       uuid.UUID
+	EmailHash    string
+	Policy       string
+	ChirpsErased int64
+}
+
+type LoginFailure struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Email     string
+	IpAddress string
+}
+
+type MagicLink struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UserID      uuid.UUID
+	TokenHash   string
+	Fingerprint string
+	ExpiresAt   time.Time
+	UsedAt      sql.NullTime
+}
+
+type MfaChallenge struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	TokenHash string
+	Attempts  int32
+	ExpiresAt time.Time
+}
+
+type MfaFailure struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+}
+
+type ModerationDecision struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	ReportID    uuid.NullUUID
+	ChirpID     uuid.NullUUID
+	AuthorID    uuid.NullUUID
+	ModeratorID uuid.NullUUID
+	Action      string
+	Note        string
+}
+
+type OauthAuthorizationCode struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	ClientID      uuid.UUID
+	UserID        uuid.UUID
+	CodeHash      string
+	RedirectUri   string
+	Scopes        string
+	CodeChallenge string
+	ExpiresAt     time.Time
+	UsedAt        sql.NullTime
+}
+
+type OauthClient struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	UserID       uuid.UUID
+	Name         string
+	SecretHash   sql.NullString
+	RedirectUris string
+	RevokedAt    sql.NullTime
+}
+
+type OidcLogin struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	StateHash    string
+	Nonce        string
+	CodeVerifier string
+	Fingerprint  string
+	ExpiresAt    time.Time
+	UsedAt       sql.NullTime
+}
+
+type PasswordReset struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
+}
+
+type PersonalAccessToken struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	Scopes     string
+	ExpiresAt  sql.NullTime
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
+type Profanity struct {
+	Word      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Mode      string
+}
+
+type RecoveryCode struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	CodeHash  string
+	UsedAt    sql.NullTime
+}
+
+type RefreshToken struct {
+	TokenHash  string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	UserID     uuid.NullUUID
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	FamilyID   uuid.UUID
+	RotatedAt  sql.NullTime
+	StartedAt  time.Time
+	LastUsedAt time.Time
+	UserAgent  string
+	IpAddress  string
+	Device     string
+	ClientID   uuid.NullUUID
+	Scopes     string
+}
+
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ChirpID    uuid.UUID
+	ReporterID uuid.NullUUID
+	Reason     string
+	Details    string
+	Status     string
+}
+
+type SigningKey struct {
+	ID         string
+	CreatedAt  time.Time
+	Algorithm  string
+	PrivateKey string
+	RetiresAt  time.Time
+	ExpiresAt  time.Time
 }
 
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Email           string
+	HashedPassword  string
+	IsChirpyRed     bool
+	SuspendedAt     sql.NullTime
+	Role            string
+	DeactivatedAt   sql.NullTime
+	Username        sql.NullString
+	DisplayName     string
+	Bio             string
+	Location        string
+	AvatarUpdatedAt sql.NullTime
+	EmailVerifiedAt sql.NullTime
+	TotpSecret      sql.NullString
+	TotpEnabledAt   sql.NullTime
+	TotpLastStep    int64
+	TokenVersion    int32
+}
+
+type UserIdentity struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Issuer    string
+	Subject   string
 }
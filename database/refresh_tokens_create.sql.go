@@ -13,34 +13,72 @@ import (
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_tokens (
+    token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id,
+    started_at, last_used_at, user_agent, ip_address, device, client_id, scopes
+)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
     $3,
-    NULL
+    NULL,
+    $4,
+    $5,
+    NOW(),
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id, rotated_at, started_at, last_used_at, user_agent, ip_address, device, client_id, scopes
 `
 
 type CreateRefreshTokenParams struct {
-	Token     string
+	TokenHash string
 	UserID    uuid.NullUUID
 	ExpiresAt time.Time
+	FamilyID  uuid.UUID
+	StartedAt time.Time
+	UserAgent string
+	IpAddress string
+	Device    string
+	ClientID  uuid.NullUUID
+	Scopes    string
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
-	row := q.db.QueryRowContext(ctx, createRefreshToken, arg.Token, arg.UserID, arg.ExpiresAt)
+	row := q.db.QueryRowContext(ctx, createRefreshToken,
+		arg.TokenHash,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.FamilyID,
+		arg.StartedAt,
+		arg.UserAgent,
+		arg.IpAddress,
+		arg.Device,
+		arg.ClientID,
+		arg.Scopes,
+	)
 	var i RefreshToken
 	err := row.Scan(
-		&i.Token,
+		&i.TokenHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.FamilyID,
+		&i.RotatedAt,
+		&i.StartedAt,
+		&i.LastUsedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+		&i.Device,
+		&i.ClientID,
+		&i.Scopes,
 	)
 	return i, err
 }
@@ -14,10 +14,10 @@ UPDATE refresh_tokens
 SET
     updated_at = NOW(),
     revoked_at = NOW()
-WHERE token = $1
+WHERE token_hash = $1 AND revoked_at IS NULL
 `
 
-func (q *Queries) DeleteRefreshToken(ctx context.Context, token string) error {
-	_, err := q.db.ExecContext(ctx, deleteRefreshToken, token)
+func (q *Queries) DeleteRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := q.db.ExecContext(ctx, deleteRefreshToken, tokenHash)
 	return err
 }
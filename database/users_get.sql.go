@@ -10,7 +10,7 @@ import (
 )
 
 const getUser = `-- name: GetUser :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, suspended_at, role, deactivated_at, username, display_name, bio, location, avatar_updated_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, token_version
 FROM users
 WHERE email = $1
 `
@@ -25,6 +25,19 @@ func (q *Queries) GetUser(ctx context.Context, email string) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarUpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.TokenVersion,
 	)
 	return i, err
 }
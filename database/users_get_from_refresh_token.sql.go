@@ -1,34 +0,0 @@
-// Code generated by sqlc. DO NOT EDIT.
-// versions:
-//   sqlc v1.30.0
-// source: users_get_from_refresh_token.sql
-
-package database
-
-import (
-	"context"
-)
-
-const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red
-FROM users
-WHERE id = (
-    SELECT user_id
-    FROM refresh_tokens
-    WHERE token = $1 AND revoked_at IS NULL AND expires_at > NOW()
-)
-`
-
-func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserFromRefreshToken, token)
-	var i User
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Email,
-		&i.HashedPassword,
-		&i.IsChirpyRed,
-	)
-	return i, err
-}
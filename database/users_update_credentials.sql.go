@@ -15,10 +15,11 @@ const updateUserCredentials = `-- name: UpdateUserCredentials :one
 UPDATE users
 SET
     updated_at = NOW(),
+    email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
     email = $2,
     hashed_password = $3
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, suspended_at, role, deactivated_at, username, display_name, bio, location, avatar_updated_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, token_version
 `
 
 type UpdateUserCredentialsParams struct {
@@ -37,6 +38,19 @@ func (q *Queries) UpdateUserCredentials(ctx context.Context, arg UpdateUserCrede
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarUpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.TokenVersion,
 	)
 	return i, err
 }
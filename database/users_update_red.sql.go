@@ -17,7 +17,7 @@ SET
     updated_at = NOW(),
     is_chirpy_red = True
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, suspended_at, role, deactivated_at, username, display_name, bio, location, avatar_updated_at, email_verified_at, totp_secret, totp_enabled_at, totp_last_step, token_version
 `
 
 func (q *Queries) UpdateUserRed(ctx context.Context, id uuid.UUID) (User, error) {
@@ -30,6 +30,19 @@ func (q *Queries) UpdateUserRed(ctx context.Context, id uuid.UUID) (User, error)
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.SuspendedAt,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.AvatarUpdatedAt,
+		&i.EmailVerifiedAt,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.TokenVersion,
 	)
 	return i, err
 }
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/mamatb/Chirpy/database"
+	"github.com/mamatb/Chirpy/web"
+)
+
+const (
+	cmdImport          = "import"
+	errorImportUsage   = "usage: chirpy import -email <email> [-format csv|ndjson] <file>"
+	errorImportNoUser  = "no user with that email"
+	importExtensionCsv = ".csv"
+	importFormatCsv    = "csv"
+	importFormatNdjson = "ndjson"
+)
+
+func runImport(config *web.ApiConfig, args []string) error {
+	var err error
+	var file *os.File
+	var user database.User
+	var result web.ImportResult
+	flags := flag.NewFlagSet(cmdImport, flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user the chirps are imported for")
+	format := flags.String("format", "", "csv or ndjson, guessed from the file extension if empty")
+	if err = flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*email) == 0 || flags.NArg() != 1 {
+		return errors.New(errorImportUsage)
+	}
+	if len(*format) == 0 {
+		*format = importFormatNdjson
+		if filepath.Ext(flags.Arg(0)) == importExtensionCsv {
+			*format = importFormatCsv
+		}
+	}
+	if user, err = config.DBQueries.GetUser(context.Background(), *email); err != nil {
+		return errors.New(errorImportNoUser)
+	}
+	if file, err = os.Open(flags.Arg(0)); err != nil {
+		return err
+	}
+	defer file.Close()
+	if result, err = config.ImportChirps(context.Background(), user, *format, file); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
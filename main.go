@@ -1,51 +1,187 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/mamatb/Chirpy/auth"
 	"github.com/mamatb/Chirpy/database"
 	"github.com/mamatb/Chirpy/web"
 )
 
 const (
-	driverName  = "postgres"
-	envDbUrl    = "DB_URL"
-	envPlatform = "PLATFORM"
-	envPolkaKey = "POLKA_KEY"
-	envSecret   = "SECRET"
-	tcpPort     = ":8080"
+	chirpMaxLength     = 140
+	chirpMaxLengthRed  = 280
+	dataExportWorkers  = 2
+	deactivationPeriod = time.Hour * 24 * 30
+	duplicateWindow    = time.Hour
+	jwtKeyOverlap      = time.Hour * 24
+	jwtKeyRotation     = time.Hour * 24 * 30
+	linkPreviewWorkers = 4
+	profanitiesReload  = time.Minute
+	signingKeysRotate  = time.Minute * 10
+	spamNewAccountAge  = time.Hour * 24 * 7
+	spamNewAccountRate = 10
+	smtpPort           = 587
+	spamThreshold      = 1.0
+	usersPurge         = time.Hour
+
+	baseUrl                = "http://localhost:8080"
+	chirpErasurePolicy     = "delete"
+	driverName             = "postgres"
+	empty                  = ""
+	envBaseUrl             = "BASE_URL"
+	envChirpErasure        = "CHIRP_ERASURE_POLICY"
+	envChirpMaxLength      = "CHIRP_MAX_LENGTH"
+	envChirpMaxLengthRed   = "CHIRP_MAX_LENGTH_RED"
+	envCookieSessions      = "COOKIE_SESSIONS"
+	envDbUrl               = "DB_URL"
+	envEncryptionKey       = "ENCRYPTION_KEY"
+	envJwtAcceptHs256      = "JWT_ACCEPT_HS256"
+	envJwtAlgorithm        = "JWT_ALGORITHM"
+	envMailDir             = "MAIL_DIR"
+	envMailFrom            = "MAIL_FROM"
+	envMailSender          = "MAIL_SENDER"
+	envMfaKey              = "MFA_KEY"
+	envOidcClientId        = "OIDC_CLIENT_ID"
+	envOidcClientSecret    = "OIDC_CLIENT_SECRET"
+	envOidcIssuer          = "OIDC_ISSUER"
+	envPlatform            = "PLATFORM"
+	envPolkaKey            = "POLKA_KEY"
+	envRequireVerified     = "REQUIRE_EMAIL_VERIFICATION"
+	envSecret              = "SECRET"
+	envSmtpHost            = "SMTP_HOST"
+	envSmtpPassword        = "SMTP_PASSWORD"
+	envSmtpPort            = "SMTP_PORT"
+	envSmtpUsername        = "SMTP_USERNAME"
+	errorMailSender        = "MAIL_SENDER has to be smtp or file unless PLATFORM is dev"
+	errorMailSenderUnknown = "unknown MAIL_SENDER %q"
+	mailDir                = "mail"
+	mailFrom               = "no-reply@localhost"
+	mailSenderFile         = "file"
+	mailSenderLog          = "log"
+	mailSenderSmtp         = "smtp"
+	oidcCallbackPath       = "/api/login/oidc/callback"
+	platformDev            = "dev"
+	tcpPort                = ":8080"
 )
 
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Fatal(err)
 	}
+	mailer, err := newMailer()
+	if err != nil {
+		log.Fatal(err)
+	}
 	mux := http.NewServeMux()
 	server := http.Server{
 		Addr:    tcpPort,
 		Handler: mux,
 	}
 	config := web.ApiConfig{
-		Platform: os.Getenv(envPlatform),
-		PolkaKey: os.Getenv(envPolkaKey),
-		Secret:   os.Getenv(envSecret),
+		Platform:                 os.Getenv(envPlatform),
+		PolkaKey:                 os.Getenv(envPolkaKey),
+		Secret:                   os.Getenv(envSecret),
+		BaseUrl:                  getenv(envBaseUrl, baseUrl),
+		ChirpMaxLength:           getenvInt(envChirpMaxLength, chirpMaxLength),
+		ChirpMaxLengthRed:        getenvInt(envChirpMaxLengthRed, chirpMaxLengthRed),
+		DuplicateWindow:          duplicateWindow,
+		DeactivationPeriod:       deactivationPeriod,
+		ChirpErasurePolicy:       getenv(envChirpErasure, chirpErasurePolicy),
+		SpamThreshold:            spamThreshold,
+		SpamNewAccountAge:        spamNewAccountAge,
+		SpamNewAccountRate:       spamNewAccountRate,
+		RequireEmailVerification: getenvBool(envRequireVerified, false),
+		CookieSessions:           getenvBool(envCookieSessions, false),
+		Mailer:                   mailer,
+		EncryptionKey:            newEncryptionKey(),
+		JwtKeyRotation:           jwtKeyRotation,
+		JwtKeyOverlap:            jwtKeyOverlap,
+		Keys: auth.NewKeySet(
+			getenv(envJwtAlgorithm, auth.AlgorithmRs256),
+			os.Getenv(envSecret),
+			getenvBool(envJwtAcceptHs256, false),
+		),
+	}
+	if err := web.ValidateErasurePolicy(config.ChirpErasurePolicy); err != nil {
+		log.Fatal(err)
 	}
+	config.Oidc = newOidcProvider(config.BaseUrl)
+	server.Handler = config.MiddleCookieSessions(mux)
 	if db, err := sql.Open(driverName, os.Getenv(envDbUrl)); err != nil {
 		log.Fatal(err)
 	} else {
 		defer db.Close()
+		config.DB = db
 		config.DBQueries = database.New(db)
 	}
-	profanities := map[string]bool{
-		"kerfuffle": true,
-		"sharbert":  true,
-		"fornax":    true,
+	config.Keys.SetTokenVersions(func(userId uuid.UUID, sessionId string) (int32, error) {
+		return config.TokenVersion(context.Background(), userId, sessionId)
+	})
+	config.RegisterChirpProcessors(web.DefaultChirpProcessors(&config)...)
+	if err := config.LoadProfanities(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	if len(os.Args) > 1 && os.Args[1] == cmdImport {
+		if err := runImport(&config, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == cmdRole {
+		if err := runRole(&config, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := config.RotateSigningKeys(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.StartLinkPreviews(context.Background(), linkPreviewWorkers); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.StartDataExports(context.Background(), dataExportWorkers); err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		for range time.Tick(profanitiesReload) {
+			if err := config.LoadProfanities(context.Background()); err != nil {
+				log.Print(err)
+			}
+		}
+	}()
+	go func() {
+		for range time.Tick(signingKeysRotate) {
+			if err := config.RotateSigningKeys(context.Background()); err != nil {
+				log.Print(err)
+			}
+		}
+	}()
+	go func() {
+		for range time.Tick(usersPurge) {
+			if err := config.PurgeDeactivatedUsers(context.Background()); err != nil {
+				log.Print(err)
+			}
+			if err := config.PurgeExpiredExports(context.Background()); err != nil {
+				log.Print(err)
+			}
+			if err := config.PurgeLoginFailures(context.Background()); err != nil {
+				log.Print(err)
+			}
+		}
+	}()
 
 	mux.HandleFunc(
 		"GET /api/health",
@@ -63,6 +199,50 @@ func main() {
 		"POST /admin/reset",
 		web.HandlerPostAdminReset(&config),
 	)
+	mux.HandleFunc(
+		"GET /admin/profanities",
+		web.HandlerGetAdminProfanities(&config),
+	)
+	mux.HandleFunc(
+		"POST /admin/profanities",
+		web.HandlerPostAdminProfanities(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /admin/profanities/{word}",
+		web.HandlerDeleteAdminProfanitiesWord(&config),
+	)
+	mux.HandleFunc(
+		"GET /admin/reports",
+		web.HandlerGetAdminReports(&config),
+	)
+	mux.HandleFunc(
+		"POST /admin/reports/{id}",
+		web.HandlerPostAdminReportsId(&config),
+	)
+	mux.HandleFunc(
+		"GET /admin/decisions",
+		web.HandlerGetAdminDecisions(&config),
+	)
+	mux.HandleFunc(
+		"PUT /admin/users/{id}/role",
+		web.HandlerPutAdminUsersIdRole(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /admin/users/{id}/role",
+		web.HandlerDeleteAdminUsersIdRole(&config),
+	)
+	mux.HandleFunc(
+		"PUT /admin/users/{id}/suspension",
+		web.HandlerPutAdminUsersIdSuspension(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /admin/users/{id}/suspension",
+		web.HandlerDeleteAdminUsersIdSuspension(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /admin/users/{id}/lockout",
+		web.HandlerDeleteAdminUsersIdLockout(&config),
+	)
 	mux.HandleFunc(
 		"POST /api/users",
 		web.HandlerPostApiUsers(&config),
@@ -71,18 +251,174 @@ func main() {
 		"PUT /api/users",
 		web.HandlerPutApiUsers(&config),
 	)
+	mux.HandleFunc(
+		"PATCH /api/users/me",
+		web.HandlerPatchApiUsersMe(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/users/{user}",
+		web.HandlerGetApiUsersUser(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/users/{id}/avatar",
+		web.HandlerGetApiUsersIdAvatar(&config),
+	)
+	mux.HandleFunc(
+		"PUT /api/users/me/avatar",
+		web.HandlerPutApiUsersMeAvatar(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /api/users/me/avatar",
+		web.HandlerDeleteApiUsersMeAvatar(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /api/users/me",
+		web.HandlerDeleteApiUsersMe(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/users/me/deactivate",
+		web.HandlerPostApiUsersMeDeactivate(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/users/me/import",
+		web.HandlerPostApiUsersMeImport(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/users/me/export",
+		web.HandlerPostApiUsersMeExport(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/users/me/export/{id}",
+		web.HandlerGetApiUsersMeExportId(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/exports/{id}/download",
+		web.HandlerGetApiExportsIdDownload(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/verify",
+		web.HandlerGetApiVerify(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/verify/resend",
+		web.HandlerPostApiVerifyResend(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/password/forgot",
+		web.HandlerPostApiPasswordForgot(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/password/reset",
+		web.HandlerPostApiPasswordReset(&config),
+	)
+	mux.HandleFunc(
+		"GET /.well-known/jwks.json",
+		web.HandlerGetWellKnownJwks(&config),
+	)
 	mux.HandleFunc(
 		"POST /api/login",
 		web.HandlerPostApiLogin(&config),
 	)
+	mux.HandleFunc(
+		"POST /api/login/magic",
+		web.HandlerPostApiLoginMagic(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/login/magic/callback",
+		web.HandlerGetApiLoginMagicCallback(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/login/oidc",
+		web.HandlerGetApiLoginOidc(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/login/oidc/callback",
+		web.HandlerGetApiLoginOidcCallback(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/login/mfa",
+		web.HandlerPostApiLoginMfa(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/users/me/mfa/totp",
+		web.HandlerPostApiUsersMeMfaTotp(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/users/me/mfa/totp/confirm",
+		web.HandlerPostApiUsersMeMfaTotpConfirm(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /api/users/me/mfa/totp",
+		web.HandlerDeleteApiUsersMeMfaTotp(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/users/me/mfa/recovery-codes",
+		web.HandlerPostApiUsersMeMfaRecoveryCodes(&config),
+	)
 	mux.HandleFunc(
 		"POST /api/refresh",
 		web.HandlerPostApiRefresh(&config),
 	)
+	mux.HandleFunc(
+		"GET /api/sessions",
+		web.HandlerGetApiSessions(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /api/sessions/{id}",
+		web.HandlerDeleteApiSessionsId(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/logout-all",
+		web.HandlerPostApiLogoutAll(&config),
+	)
+	mux.HandleFunc(
+		"POST /api/tokens",
+		web.HandlerPostApiTokens(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/tokens",
+		web.HandlerGetApiTokens(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /api/tokens/{id}",
+		web.HandlerDeleteApiTokensId(&config),
+	)
 	mux.HandleFunc(
 		"POST /api/revoke",
 		web.HandlerPostApiRevoke(&config),
 	)
+	mux.HandleFunc(
+		"POST /api/oauth/clients",
+		web.HandlerPostApiOauthClients(&config),
+	)
+	mux.HandleFunc(
+		"GET /api/oauth/clients",
+		web.HandlerGetApiOauthClients(&config),
+	)
+	mux.HandleFunc(
+		"DELETE /api/oauth/clients/{id}",
+		web.HandlerDeleteApiOauthClientsId(&config),
+	)
+	mux.HandleFunc(
+		"GET /oauth/authorize",
+		web.HandlerGetOauthAuthorize(&config),
+	)
+	mux.HandleFunc(
+		"POST /oauth/authorize",
+		web.HandlerPostOauthAuthorize(&config),
+	)
+	mux.HandleFunc(
+		"POST /oauth/token",
+		web.HandlerPostOauthToken(&config),
+	)
+	mux.HandleFunc(
+		"POST /oauth/revoke",
+		web.HandlerPostOauthRevoke(&config),
+	)
+	mux.HandleFunc(
+		"POST /oauth/introspect",
+		web.HandlerPostOauthIntrospect(&config),
+	)
 	mux.HandleFunc(
 		"GET /api/chirps/{id}",
 		web.HandlerGetApiChirpsId(&config),
@@ -93,12 +429,20 @@ func main() {
 	)
 	mux.HandleFunc(
 		"POST /api/chirps",
-		web.HandlerPostApiChirps(&config, profanities),
+		web.HandlerPostApiChirps(&config),
 	)
 	mux.HandleFunc(
 		"DELETE /api/chirps/{id}",
 		web.HandlerDeleteApiChirpsId(&config),
 	)
+	mux.HandleFunc(
+		"POST /api/chirps/{id}/report",
+		web.HandlerPostApiChirpsIdReport(&config),
+	)
+	mux.HandleFunc(
+		"GET /l/{code}",
+		web.HandlerGetLCode(&config),
+	)
 	mux.HandleFunc(
 		"POST /api/polka/webhooks",
 		web.HandlerPostApiPolkaWebhooks(&config),
@@ -106,3 +450,73 @@ func main() {
 
 	log.Fatal(server.ListenAndServe())
 }
+
+func getenv(key string, fallback string) string {
+	if value := os.Getenv(key); len(value) > 0 {
+		return value
+	}
+	return fallback
+}
+
+func getenvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func getenvBool(key string, fallback bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return fallback
+}
+
+// newMailer only falls back to writing mail, and the tokens in it, to the log
+// on dev platforms, so a production server missing MAIL_SENDER fails to start.
+func newMailer() (web.Mailer, error) {
+	from := getenv(envMailFrom, mailFrom)
+	switch sender := os.Getenv(envMailSender); sender {
+	case mailSenderSmtp:
+		return web.SmtpMailer{
+			Host:     os.Getenv(envSmtpHost),
+			Port:     getenvInt(envSmtpPort, smtpPort),
+			Username: os.Getenv(envSmtpUsername),
+			Password: os.Getenv(envSmtpPassword),
+			From:     from,
+		}, nil
+	case mailSenderFile:
+		return web.FileMailer{Dir: getenv(envMailDir, mailDir), From: from}, nil
+	case empty, mailSenderLog:
+		if os.Getenv(envPlatform) != platformDev {
+			return nil, errors.New(errorMailSender)
+		}
+		return web.LogMailer{From: from}, nil
+	default:
+		return nil, fmt.Errorf(errorMailSenderUnknown, sender)
+	}
+}
+
+// newEncryptionKey derives the AES-256 key that encrypts totp secrets and
+// signing keys at rest. It comes from ENCRYPTION_KEY, or MFA_KEY as it was
+// called before, and only falls back to SECRET when neither is set, in which
+// case rotating SECRET makes every stored secret unreadable.
+func newEncryptionKey() []byte {
+	key := sha256.Sum256([]byte(getenv(envEncryptionKey, getenv(envMfaKey, os.Getenv(envSecret)))))
+	return key[:]
+}
+
+// newOidcProvider enables logging in through an external identity provider
+// when OIDC_ISSUER is set, which has to be registered with the callback url.
+func newOidcProvider(baseUrl string) *auth.OidcProvider {
+	issuer := os.Getenv(envOidcIssuer)
+	if len(issuer) == 0 {
+		return nil
+	}
+	return auth.NewOidcProvider(
+		issuer,
+		os.Getenv(envOidcClientId),
+		os.Getenv(envOidcClientSecret),
+		baseUrl+oidcCallbackPath,
+	)
+}
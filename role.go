@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mamatb/Chirpy/database"
+	"github.com/mamatb/Chirpy/web"
+)
+
+const (
+	cmdRole          = "role"
+	errorRoleUsage   = "usage: chirpy role -email <email> user|moderator|admin"
+	errorRoleNoUser  = "no user with that email"
+	messageRoleGiven = "%s is now %s\n"
+)
+
+// runRole sets the role of a user from the command line, which is how the
+// first admin is made before anyone can do it through the api.
+func runRole(config *web.ApiConfig, args []string) error {
+	var err error
+	var user database.User
+	flags := flag.NewFlagSet(cmdRole, flag.ContinueOnError)
+	email := flags.String("email", "", "email of the user whose role is set")
+	if err = flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*email) == 0 || flags.NArg() != 1 || !web.IsRole(flags.Arg(0)) {
+		return errors.New(errorRoleUsage)
+	}
+	if user, err = config.DBQueries.GetUser(context.Background(), *email); err != nil {
+		return errors.New(errorRoleNoUser)
+	}
+	if user, err = config.DBQueries.UpdateUserRole(
+		context.Background(),
+		database.UpdateUserRoleParams{
+			ID:   user.ID,
+			Role: flags.Arg(0),
+		},
+	); err != nil {
+		return err
+	}
+	fmt.Printf(messageRoleGiven, user.Email, user.Role)
+	return nil
+}
@@ -0,0 +1,4 @@
+-- name: DeleteAvatar :exec
+DELETE
+FROM avatars
+WHERE user_id = $1;
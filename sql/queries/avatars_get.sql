@@ -0,0 +1,4 @@
+-- name: GetAvatar :one
+SELECT *
+FROM avatars
+WHERE user_id = $1;
@@ -0,0 +1,14 @@
+-- name: UpsertAvatar :exec
+INSERT INTO avatars (user_id, created_at, updated_at, content_type, image)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2,
+    $3
+)
+ON CONFLICT (user_id) DO UPDATE
+SET
+    updated_at = NOW(),
+    content_type = EXCLUDED.content_type,
+    image = EXCLUDED.image;
@@ -0,0 +1,12 @@
+-- name: CreateChirpLink :one
+INSERT INTO chirp_links (id, created_at, updated_at, chirp_id, position, url, code)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
@@ -0,0 +1,5 @@
+-- name: GetChirpLinksFromChirps :many
+SELECT *
+FROM chirp_links
+WHERE chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_id, position ASC;
@@ -0,0 +1,4 @@
+-- name: GetChirpLinkFromCode :one
+SELECT *
+FROM chirp_links
+WHERE code = $1;
@@ -0,0 +1,5 @@
+-- name: GetChirpLinksPending :many
+SELECT *
+FROM chirp_links
+WHERE fetched_at IS NULL
+ORDER BY created_at ASC;
@@ -0,0 +1,11 @@
+-- name: UpdateChirpLinkPreview :exec
+UPDATE chirp_links
+SET
+    updated_at = NOW(),
+    fetched_at = NOW(),
+    title = $2,
+    description = $3,
+    image_url = $4,
+    site_name = $5,
+    fetch_error = $6
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- name: CountChirpsDuplicates :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1 AND fingerprint = $2 AND created_at > $3;
@@ -0,0 +1,4 @@
+-- name: CountChirpsFromUser :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1 AND created_at > $2;
@@ -0,0 +1,13 @@
+-- name: CreateChirpImported :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, status, fingerprint, spam_score)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
@@ -0,0 +1,4 @@
+-- name: DeleteChirpsFromUser :execrows
+DELETE
+FROM chirps
+WHERE user_id = $1;
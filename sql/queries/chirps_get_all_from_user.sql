@@ -0,0 +1,5 @@
+-- name: GetChirpsAllFromUser :many
+SELECT *
+FROM chirps
+WHERE user_id = $1
+ORDER BY created_at ASC;
@@ -1,5 +1,9 @@
 -- name: GetChirpsFromUser :many
 SELECT *
 FROM chirps
-WHERE user_id = $1
+WHERE user_id = $1 AND status = 'published' AND NOT EXISTS (
+    SELECT 1
+    FROM users
+    WHERE users.id = chirps.user_id AND (suspended_at IS NOT NULL OR deactivated_at IS NOT NULL)
+)
 ORDER BY created_at ASC;
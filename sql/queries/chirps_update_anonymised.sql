@@ -0,0 +1,6 @@
+-- name: UpdateChirpsAnonymised :execrows
+UPDATE chirps
+SET
+    updated_at = NOW(),
+    user_id = NULL
+WHERE user_id = $1;
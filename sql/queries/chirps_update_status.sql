@@ -0,0 +1,6 @@
+-- name: UpdateChirpStatus :exec
+UPDATE chirps
+SET
+    updated_at = NOW(),
+    status = $2
+WHERE id = $1;
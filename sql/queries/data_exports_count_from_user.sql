@@ -0,0 +1,4 @@
+-- name: CountDataExportsFromUser :one
+SELECT COUNT(*)
+FROM data_exports
+WHERE user_id = $1 AND created_at > $2;
@@ -0,0 +1,4 @@
+-- name: DeleteDataExportsExpired :exec
+DELETE
+FROM data_exports
+WHERE expires_at < NOW();
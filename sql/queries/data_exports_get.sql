@@ -0,0 +1,4 @@
+-- name: GetDataExport :one
+SELECT *
+FROM data_exports
+WHERE id = $1 AND expires_at > NOW();
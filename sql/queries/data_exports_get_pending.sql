@@ -0,0 +1,5 @@
+-- name: GetDataExportsPending :many
+SELECT *
+FROM data_exports
+WHERE status = 'pending'
+ORDER BY created_at ASC;
@@ -0,0 +1,6 @@
+-- name: UpdateDataExportFailed :exec
+UPDATE data_exports
+SET
+    updated_at = NOW(),
+    status = 'failed'
+WHERE id = $1;
@@ -0,0 +1,7 @@
+-- name: UpdateDataExportReady :exec
+UPDATE data_exports
+SET
+    updated_at = NOW(),
+    status = 'ready',
+    archive = $2
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- name: CountEmailVerificationsFromUser :one
+SELECT COUNT(*)
+FROM email_verifications
+WHERE user_id = $1 AND created_at > $2;
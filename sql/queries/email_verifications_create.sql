@@ -0,0 +1,11 @@
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (id, created_at, user_id, email, token_hash, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
@@ -0,0 +1,4 @@
+-- name: GetEmailVerificationFromHash :one
+SELECT *
+FROM email_verifications
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW();
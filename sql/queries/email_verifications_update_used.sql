@@ -0,0 +1,4 @@
+-- name: UpdateEmailVerificationUsed :exec
+UPDATE email_verifications
+SET used_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,11 @@
+-- name: CreateErasureReceipt :one
+INSERT INTO erasure_receipts (id, created_at, user_id, email_hash, policy, chirps_erased)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
@@ -0,0 +1,9 @@
+-- name: CreateLoginFailure :one
+INSERT INTO login_failures (id, created_at, email, ip_address)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2
+)
+RETURNING *;
@@ -0,0 +1,4 @@
+-- name: DeleteLoginFailure :exec
+DELETE
+FROM login_failures
+WHERE id = $1;
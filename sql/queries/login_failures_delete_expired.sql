@@ -0,0 +1,4 @@
+-- name: DeleteLoginFailuresExpired :exec
+DELETE
+FROM login_failures
+WHERE created_at < $1;
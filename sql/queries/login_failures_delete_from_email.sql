@@ -0,0 +1,4 @@
+-- name: DeleteLoginFailuresFromEmail :exec
+DELETE
+FROM login_failures
+WHERE email = $1;
@@ -0,0 +1,8 @@
+-- name: DeleteLoginFailuresFromEmailAndIpAddresses :exec
+DELETE
+FROM login_failures
+WHERE email = $1 OR ip_address IN (
+    SELECT ip_address
+    FROM login_failures
+    WHERE email = $1
+);
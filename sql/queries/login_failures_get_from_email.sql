@@ -0,0 +1,5 @@
+-- name: GetLoginFailuresFromEmail :many
+SELECT created_at
+FROM login_failures
+WHERE email = $1 AND created_at > $2 AND id <> $3
+ORDER BY created_at DESC;
@@ -0,0 +1,4 @@
+-- name: CountMagicLinksFromUser :one
+SELECT COUNT(*)
+FROM magic_links
+WHERE user_id = $1 AND created_at > $2;
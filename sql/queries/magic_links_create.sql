@@ -0,0 +1,11 @@
+-- name: CreateMagicLink :one
+INSERT INTO magic_links (id, created_at, user_id, token_hash, fingerprint, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
@@ -0,0 +1,5 @@
+-- name: UpdateMagicLinkUsed :one
+UPDATE magic_links
+SET used_at = NOW()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
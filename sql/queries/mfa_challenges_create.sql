@@ -0,0 +1,10 @@
+-- name: CreateMfaChallenge :one
+INSERT INTO mfa_challenges (id, created_at, user_id, token_hash, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
@@ -0,0 +1,4 @@
+-- name: DeleteMfaChallenge :exec
+DELETE
+FROM mfa_challenges
+WHERE id = $1;
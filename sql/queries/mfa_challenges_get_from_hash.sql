@@ -0,0 +1,4 @@
+-- name: GetMfaChallengeFromHash :one
+SELECT *
+FROM mfa_challenges
+WHERE token_hash = $1 AND expires_at > NOW();
@@ -0,0 +1,5 @@
+-- name: UpdateMfaChallengeAttempts :one
+UPDATE mfa_challenges
+SET attempts = attempts + 1
+WHERE id = $1
+RETURNING attempts;
@@ -0,0 +1,8 @@
+-- name: CreateMfaFailure :one
+INSERT INTO mfa_failures (id, created_at, user_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1
+)
+RETURNING *;
@@ -0,0 +1,4 @@
+-- name: DeleteMfaFailure :exec
+DELETE
+FROM mfa_failures
+WHERE id = $1;
@@ -0,0 +1,4 @@
+-- name: DeleteMfaFailuresExpired :exec
+DELETE
+FROM mfa_failures
+WHERE created_at < $1;
@@ -0,0 +1,4 @@
+-- name: DeleteMfaFailuresFromUser :exec
+DELETE
+FROM mfa_failures
+WHERE user_id = $1;
@@ -0,0 +1,5 @@
+-- name: GetMfaFailuresFromUser :many
+SELECT created_at
+FROM mfa_failures
+WHERE user_id = $1 AND created_at > $2 AND id <> $3
+ORDER BY created_at DESC;
@@ -0,0 +1,13 @@
+-- name: CreateModerationDecision :one
+INSERT INTO moderation_decisions (id, created_at, report_id, chirp_id, author_id, moderator_id, action, note)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+)
+RETURNING *;
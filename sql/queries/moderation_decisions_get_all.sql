@@ -0,0 +1,4 @@
+-- name: GetModerationDecisions :many
+SELECT *
+FROM moderation_decisions
+ORDER BY created_at ASC;
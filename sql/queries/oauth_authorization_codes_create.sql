@@ -0,0 +1,16 @@
+-- name: CreateOauthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+    id, created_at, client_id, user_id, code_hash, redirect_uri, scopes, code_challenge, expires_at
+)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
@@ -0,0 +1,5 @@
+-- name: UpdateOauthAuthorizationCodeUsed :one
+UPDATE oauth_authorization_codes
+SET used_at = NOW()
+WHERE code_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
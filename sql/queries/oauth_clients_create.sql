@@ -0,0 +1,12 @@
+-- name: CreateOauthClient :one
+INSERT INTO oauth_clients (id, created_at, updated_at, user_id, name, secret_hash, redirect_uris)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
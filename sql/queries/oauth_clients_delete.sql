@@ -0,0 +1,6 @@
+-- name: DeleteOauthClient :execrows
+UPDATE oauth_clients
+SET
+    updated_at = NOW(),
+    revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
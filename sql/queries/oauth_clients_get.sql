@@ -0,0 +1,4 @@
+-- name: GetOauthClient :one
+SELECT *
+FROM oauth_clients
+WHERE id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,5 @@
+-- name: GetOauthClientsFromUser :many
+SELECT *
+FROM oauth_clients
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at ASC;
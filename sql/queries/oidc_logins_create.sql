@@ -0,0 +1,11 @@
+-- name: CreateOidcLogin :exec
+INSERT INTO oidc_logins (id, created_at, state_hash, nonce, code_verifier, fingerprint, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+);
@@ -0,0 +1,5 @@
+-- name: UpdateOidcLoginUsed :one
+UPDATE oidc_logins
+SET used_at = NOW()
+WHERE state_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
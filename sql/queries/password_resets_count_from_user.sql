@@ -0,0 +1,4 @@
+-- name: CountPasswordResetsFromUser :one
+SELECT COUNT(*)
+FROM password_resets
+WHERE user_id = $1 AND created_at > $2;
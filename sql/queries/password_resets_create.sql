@@ -0,0 +1,10 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (id, created_at, user_id, token_hash, expires_at)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
@@ -0,0 +1,5 @@
+-- name: UpdatePasswordResetUsed :one
+UPDATE password_resets
+SET used_at = NOW()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
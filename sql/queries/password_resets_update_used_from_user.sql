@@ -0,0 +1,4 @@
+-- name: UpdatePasswordResetsUsedFromUser :exec
+UPDATE password_resets
+SET used_at = NOW()
+WHERE user_id = $1 AND used_at IS NULL;
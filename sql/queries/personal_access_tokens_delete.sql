@@ -0,0 +1,6 @@
+-- name: DeletePersonalAccessToken :execrows
+UPDATE personal_access_tokens
+SET
+    updated_at = NOW(),
+    revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
@@ -0,0 +1,4 @@
+-- name: GetPersonalAccessTokenFromHash :one
+SELECT *
+FROM personal_access_tokens
+WHERE token_hash = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW());
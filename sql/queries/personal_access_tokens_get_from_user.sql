@@ -0,0 +1,5 @@
+-- name: GetPersonalAccessTokensFromUser :many
+SELECT *
+FROM personal_access_tokens
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at ASC;
@@ -0,0 +1,4 @@
+-- name: UpdatePersonalAccessTokenUsed :exec
+UPDATE personal_access_tokens
+SET last_used_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,13 @@
+-- name: CreateProfanity :one
+INSERT INTO profanities (word, created_at, updated_at, mode)
+VALUES (
+    $1,
+    NOW(),
+    NOW(),
+    $2
+)
+ON CONFLICT (word) DO UPDATE
+SET
+    updated_at = NOW(),
+    mode = EXCLUDED.mode
+RETURNING *;
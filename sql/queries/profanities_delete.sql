@@ -0,0 +1,4 @@
+-- name: DeleteProfanity :exec
+DELETE
+FROM profanities
+WHERE word = $1;
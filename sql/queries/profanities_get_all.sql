@@ -0,0 +1,4 @@
+-- name: GetProfanities :many
+SELECT *
+FROM profanities
+ORDER BY word ASC;
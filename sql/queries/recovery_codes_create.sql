@@ -0,0 +1,8 @@
+-- name: CreateRecoveryCode :exec
+INSERT INTO recovery_codes (id, created_at, user_id, code_hash)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2
+);
@@ -0,0 +1,4 @@
+-- name: DeleteRecoveryCodesFromUser :exec
+DELETE
+FROM recovery_codes
+WHERE user_id = $1;
@@ -0,0 +1,4 @@
+-- name: UpdateRecoveryCodeUsed :execrows
+UPDATE recovery_codes
+SET used_at = NOW()
+WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL;
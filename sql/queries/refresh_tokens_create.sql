@@ -1,11 +1,22 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_tokens (
+    token_hash, created_at, updated_at, user_id, expires_at, revoked_at, family_id,
+    started_at, last_used_at, user_agent, ip_address, device, client_id, scopes
+)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
     $3,
-    NULL
+    NULL,
+    $4,
+    $5,
+    NOW(),
+    $6,
+    $7,
+    $8,
+    $9,
+    $10
 )
 RETURNING *;
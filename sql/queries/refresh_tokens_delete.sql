@@ -3,4 +3,4 @@ UPDATE refresh_tokens
 SET
     updated_at = NOW(),
     revoked_at = NOW()
-WHERE token = $1;
+WHERE token_hash = $1 AND revoked_at IS NULL;
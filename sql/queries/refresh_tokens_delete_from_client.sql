@@ -0,0 +1,6 @@
+-- name: DeleteRefreshTokensFromClient :exec
+UPDATE refresh_tokens
+SET
+    updated_at = NOW(),
+    revoked_at = NOW()
+WHERE client_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,6 @@
+-- name: DeleteRefreshTokensFromFamily :exec
+UPDATE refresh_tokens
+SET
+    updated_at = NOW(),
+    revoked_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,6 @@
+-- name: DeleteRefreshTokensFromUser :exec
+UPDATE refresh_tokens
+SET
+    updated_at = NOW(),
+    revoked_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
@@ -0,0 +1,6 @@
+-- name: DeleteSession :execrows
+UPDATE refresh_tokens
+SET
+    updated_at = NOW(),
+    revoked_at = NOW()
+WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL;
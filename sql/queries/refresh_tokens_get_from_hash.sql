@@ -0,0 +1,5 @@
+-- name: GetRefreshTokenFromHash :one
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE;
@@ -0,0 +1,5 @@
+-- name: GetRefreshTokensFromUser :many
+SELECT *
+FROM refresh_tokens
+WHERE user_id = $1
+ORDER BY created_at ASC;
@@ -0,0 +1,5 @@
+-- name: GetSessionsFromUser :many
+SELECT *
+FROM refresh_tokens
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY last_used_at DESC;
@@ -0,0 +1,7 @@
+-- name: UpdateRefreshTokenRotated :exec
+UPDATE refresh_tokens
+SET
+    updated_at = NOW(),
+    revoked_at = NOW(),
+    rotated_at = NOW()
+WHERE token_hash = $1;
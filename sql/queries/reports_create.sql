@@ -0,0 +1,12 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, chirp_id, reporter_id, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
@@ -0,0 +1,4 @@
+-- name: GetReport :one
+SELECT *
+FROM reports
+WHERE id = $1;
@@ -0,0 +1,5 @@
+-- name: GetReportsOpen :many
+SELECT *
+FROM reports
+WHERE status = 'open'
+ORDER BY created_at ASC;
@@ -0,0 +1,6 @@
+-- name: ResolveReport :exec
+UPDATE reports
+SET
+    updated_at = NOW(),
+    status = 'resolved'
+WHERE id = $1;
@@ -0,0 +1,11 @@
+-- name: CreateSigningKey :one
+INSERT INTO signing_keys (id, created_at, algorithm, private_key, retires_at, expires_at)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    $3,
+    $4,
+    $5
+)
+RETURNING *;
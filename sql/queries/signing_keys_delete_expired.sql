@@ -0,0 +1,3 @@
+-- name: DeleteSigningKeysExpired :exec
+DELETE FROM signing_keys
+WHERE expires_at <= NOW();
@@ -0,0 +1,5 @@
+-- name: GetSigningKeys :many
+SELECT *
+FROM signing_keys
+WHERE expires_at > NOW()
+ORDER BY retires_at ASC;
@@ -0,0 +1,9 @@
+-- name: CreateUserIdentity :exec
+INSERT INTO user_identities (id, created_at, user_id, issuer, subject)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+);
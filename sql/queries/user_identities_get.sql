@@ -0,0 +1,4 @@
+-- name: GetUserIdentity :one
+SELECT *
+FROM user_identities
+WHERE issuer = $1 AND subject = $2;
@@ -0,0 +1,4 @@
+-- name: DeleteUser :exec
+DELETE
+FROM users
+WHERE id = $1;
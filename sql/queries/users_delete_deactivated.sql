@@ -0,0 +1,4 @@
+-- name: DeleteUsersDeactivated :exec
+DELETE
+FROM users
+WHERE deactivated_at < $1;
@@ -0,0 +1,4 @@
+-- name: GetUserFromId :one
+SELECT *
+FROM users
+WHERE id = $1;
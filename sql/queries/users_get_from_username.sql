@@ -0,0 +1,4 @@
+-- name: GetUserFromUsername :one
+SELECT *
+FROM users
+WHERE username = $1;
@@ -0,0 +1,4 @@
+-- name: GetUserTokenVersion :one
+SELECT token_version
+FROM users
+WHERE id = $1;
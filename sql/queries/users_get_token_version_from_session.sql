@@ -0,0 +1,8 @@
+-- name: GetUserTokenVersionFromSession :one
+SELECT token_version
+FROM users
+WHERE id = $1 AND EXISTS (
+    SELECT 1
+    FROM refresh_tokens
+    WHERE user_id = users.id AND family_id = $2 AND revoked_at IS NULL AND expires_at > NOW()
+);
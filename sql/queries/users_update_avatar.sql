@@ -0,0 +1,6 @@
+-- name: UpdateUserAvatar :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    avatar_updated_at = $2
+WHERE id = $1;
@@ -2,6 +2,7 @@
 UPDATE users
 SET
     updated_at = NOW(),
+    email_verified_at = CASE WHEN email = $2 THEN email_verified_at END,
     email = $2,
     hashed_password = $3
 WHERE id = $1
@@ -0,0 +1,6 @@
+-- name: UpdateUserDeactivated :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    deactivated_at = NOW()
+WHERE id = $1;
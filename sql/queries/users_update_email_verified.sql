@@ -0,0 +1,6 @@
+-- name: UpdateUserEmailVerified :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    email_verified_at = NOW()
+WHERE id = $1 AND email = $2;
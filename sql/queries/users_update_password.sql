@@ -0,0 +1,6 @@
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    hashed_password = $2
+WHERE id = $1;
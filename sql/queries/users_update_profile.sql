@@ -0,0 +1,10 @@
+-- name: UpdateUserProfile :one
+UPDATE users
+SET
+    updated_at = NOW(),
+    username = $2,
+    display_name = $3,
+    bio = $4,
+    location = $5
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,7 @@
+-- name: UpdateUserReactivated :one
+UPDATE users
+SET
+    updated_at = NOW(),
+    deactivated_at = NULL
+WHERE id = $1
+RETURNING *;
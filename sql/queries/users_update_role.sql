@@ -0,0 +1,8 @@
+-- name: UpdateUserRole :one
+UPDATE users
+SET
+    updated_at = NOW(),
+    role = $2,
+    token_version = token_version + 1
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,6 @@
+-- name: UpdateUserSuspended :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    suspended_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,7 @@
+-- name: UpdateUserTokenVersion :one
+UPDATE users
+SET
+    updated_at = NOW(),
+    token_version = token_version + 1
+WHERE id = $1
+RETURNING *;
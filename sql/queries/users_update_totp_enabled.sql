@@ -0,0 +1,7 @@
+-- name: UpdateUserTotpEnabled :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    totp_enabled_at = NOW(),
+    totp_last_step = $2
+WHERE id = $1;
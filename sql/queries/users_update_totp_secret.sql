@@ -0,0 +1,8 @@
+-- name: UpdateUserTotpSecret :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    totp_secret = $2,
+    totp_enabled_at = NULL,
+    totp_last_step = 0
+WHERE id = $1;
@@ -0,0 +1,6 @@
+-- name: UpdateUserUnsuspended :exec
+UPDATE users
+SET
+    updated_at = NOW(),
+    suspended_at = NULL
+WHERE id = $1;
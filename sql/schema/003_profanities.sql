@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE profanities (
+    word text PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    mode text NOT NULL DEFAULT 'word' CHECK (mode IN ('word', 'substring'))
+);
+INSERT INTO profanities (word, created_at, updated_at)
+VALUES
+    ('kerfuffle', NOW(), NOW()),
+    ('sharbert', NOW(), NOW()),
+    ('fornax', NOW(), NOW());
+
+-- +goose Down
+DROP TABLE profanities;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE chirp_links (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    chirp_id uuid NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    position integer NOT NULL,
+    url text NOT NULL,
+    code text UNIQUE NOT NULL,
+    title text,
+    description text,
+    image_url text,
+    site_name text,
+    fetched_at timestamp,
+    fetch_error text
+);
+
+-- +goose Down
+DROP TABLE chirp_links;
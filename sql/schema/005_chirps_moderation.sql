@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE chirps
+    ADD COLUMN status text NOT NULL DEFAULT 'published'
+        CHECK (status IN ('published', 'moderation')),
+    ADD COLUMN fingerprint text NOT NULL DEFAULT '',
+    ADD COLUMN spam_score double precision NOT NULL DEFAULT 0;
+CREATE INDEX chirps_user_id_fingerprint_idx ON chirps (user_id, fingerprint, created_at);
+
+-- +goose Down
+DROP INDEX chirps_user_id_fingerprint_idx;
+ALTER TABLE chirps
+    DROP COLUMN spam_score,
+    DROP COLUMN fingerprint,
+    DROP COLUMN status;
@@ -0,0 +1,38 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN suspended_at timestamp;
+ALTER TABLE chirps
+    DROP CONSTRAINT chirps_status_check,
+    ADD CONSTRAINT chirps_status_check
+        CHECK (status IN ('published', 'moderation', 'hidden'));
+CREATE TABLE reports (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    chirp_id uuid NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reporter_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    reason text NOT NULL CHECK (reason IN ('spam', 'abuse', 'harassment', 'other')),
+    details text NOT NULL DEFAULT '',
+    status text NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved'))
+);
+CREATE TABLE moderation_decisions (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    report_id uuid REFERENCES reports(id) ON DELETE SET NULL,
+    chirp_id uuid,
+    author_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    moderator_id uuid REFERENCES users(id) ON DELETE SET NULL,
+    action text NOT NULL CHECK (action IN ('dismiss', 'hide', 'suspend')),
+    note text NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE moderation_decisions;
+DROP TABLE reports;
+UPDATE chirps SET status = 'moderation' WHERE status = 'hidden';
+ALTER TABLE chirps
+    DROP CONSTRAINT chirps_status_check,
+    ADD CONSTRAINT chirps_status_check
+        CHECK (status IN ('published', 'moderation'));
+ALTER TABLE users
+    DROP COLUMN suspended_at;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN deactivated_at timestamp;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN deactivated_at;
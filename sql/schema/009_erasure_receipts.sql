@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE erasure_receipts (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL,
+    email_hash text NOT NULL,
+    policy text NOT NULL CHECK (policy IN ('delete', 'anonymise')),
+    chirps_erased bigint NOT NULL
+);
+
+-- +goose Down
+DROP TABLE erasure_receipts;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE data_exports (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready', 'failed')),
+    archive bytea,
+    expires_at timestamp NOT NULL
+);
+
+-- +goose Down
+DROP TABLE data_exports;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN username text UNIQUE,
+    ADD COLUMN display_name text NOT NULL DEFAULT '',
+    ADD COLUMN bio text NOT NULL DEFAULT '',
+    ADD COLUMN location text NOT NULL DEFAULT '',
+    ADD COLUMN avatar_updated_at timestamp;
+
+CREATE TABLE avatars (
+    user_id uuid PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    content_type text NOT NULL,
+    image bytea NOT NULL
+);
+
+-- +goose Down
+DROP TABLE avatars;
+
+ALTER TABLE users
+    DROP COLUMN avatar_updated_at,
+    DROP COLUMN location,
+    DROP COLUMN bio,
+    DROP COLUMN display_name,
+    DROP COLUMN username;
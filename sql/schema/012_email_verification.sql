@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN email_verified_at timestamp;
+
+CREATE TABLE email_verifications (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email text NOT NULL,
+    token_hash text NOT NULL UNIQUE,
+    expires_at timestamp NOT NULL,
+    used_at timestamp
+);
+
+-- +goose Down
+DROP TABLE email_verifications;
+
+ALTER TABLE users
+    DROP COLUMN email_verified_at;
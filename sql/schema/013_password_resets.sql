@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE password_resets (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash text NOT NULL UNIQUE,
+    expires_at timestamp NOT NULL,
+    used_at timestamp
+);
+
+-- +goose Down
+DROP TABLE password_resets;
@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN totp_secret text,
+    ADD COLUMN totp_enabled_at timestamp,
+    ADD COLUMN totp_last_step bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE recovery_codes (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash text NOT NULL,
+    used_at timestamp,
+    UNIQUE (user_id, code_hash)
+);
+
+CREATE TABLE mfa_challenges (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash text NOT NULL UNIQUE,
+    attempts integer NOT NULL DEFAULT 0,
+    expires_at timestamp NOT NULL
+);
+
+-- +goose Down
+DROP TABLE mfa_challenges;
+
+DROP TABLE recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN totp_last_step,
+    DROP COLUMN totp_enabled_at,
+    DROP COLUMN totp_secret;
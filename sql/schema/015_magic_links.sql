@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE magic_links (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash text NOT NULL UNIQUE,
+    fingerprint text NOT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp
+);
+
+-- +goose Down
+DROP TABLE magic_links;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE refresh_tokens
+ADD COLUMN started_at timestamp NOT NULL DEFAULT NOW(),
+ADD COLUMN last_used_at timestamp NOT NULL DEFAULT NOW(),
+ADD COLUMN user_agent text NOT NULL DEFAULT '',
+ADD COLUMN ip_address text NOT NULL DEFAULT '',
+ADD COLUMN device text NOT NULL DEFAULT '';
+
+UPDATE refresh_tokens
+SET
+    started_at = created_at,
+    last_used_at = updated_at;
+
+ALTER TABLE users
+ADD COLUMN token_version integer NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN token_version;
+
+ALTER TABLE refresh_tokens
+DROP COLUMN device,
+DROP COLUMN ip_address,
+DROP COLUMN user_agent,
+DROP COLUMN last_used_at,
+DROP COLUMN started_at;
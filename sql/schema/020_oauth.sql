@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE oauth_clients (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+    secret_hash text,
+    redirect_uris text NOT NULL,
+    revoked_at timestamp
+);
+
+CREATE TABLE oauth_authorization_codes (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    client_id uuid NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash text NOT NULL UNIQUE,
+    redirect_uri text NOT NULL,
+    scopes text NOT NULL,
+    code_challenge text NOT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp
+);
+
+ALTER TABLE refresh_tokens
+ADD COLUMN client_id uuid REFERENCES oauth_clients(id) ON DELETE CASCADE,
+ADD COLUMN scopes text NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE refresh_tokens
+DROP COLUMN scopes,
+DROP COLUMN client_id;
+
+DROP TABLE oauth_authorization_codes;
+DROP TABLE oauth_clients;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE oidc_logins (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    state_hash text NOT NULL UNIQUE,
+    nonce text NOT NULL,
+    code_verifier text NOT NULL,
+    fingerprint text NOT NULL,
+    expires_at timestamp NOT NULL,
+    used_at timestamp
+);
+
+CREATE TABLE user_identities (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer text NOT NULL,
+    subject text NOT NULL,
+    UNIQUE (issuer, subject)
+);
+
+-- +goose Down
+DROP TABLE user_identities;
+DROP TABLE oidc_logins;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE mfa_failures (
+    id uuid PRIMARY KEY,
+    created_at timestamp NOT NULL,
+    user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX mfa_failures_user_id_idx ON mfa_failures (user_id, created_at);
+
+-- +goose Down
+DROP TABLE mfa_failures;
@@ -0,0 +1,147 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+// ValidateErasurePolicy fails on anything but delete or anonymise, so that a
+// typo stops the server instead of erasing chirps that were meant to stay.
+func ValidateErasurePolicy(policy string) error {
+	if policy != erasurePolicyDelete && policy != erasurePolicyAnonymise {
+		return fmt.Errorf(errorInvalidErasurePolicy, policy)
+	}
+	return nil
+}
+
+func (c *ApiConfig) PurgeDeactivatedUsers(ctx context.Context) error {
+	return c.DBQueries.DeleteUsersDeactivated(ctx, sql.NullTime{
+		Time:  time.Now().Add(-c.DeactivationPeriod),
+		Valid: true,
+	})
+}
+
+// suspendUser blocks a user and revokes every session and personal access
+// token they have, so neither they nor their bots keep working.
+func suspendUser(ctx context.Context, queries *database.Queries, userId uuid.UUID) error {
+	if err := queries.UpdateUserSuspended(ctx, userId); err != nil {
+		return err
+	}
+	if err := queries.DeleteRefreshTokensFromUser(ctx, uuid.NullUUID{UUID: userId, Valid: true}); err != nil {
+		return err
+	}
+	return queries.DeletePersonalAccessTokensFromUser(ctx, userId)
+}
+
+// suspend runs suspendUser in a transaction of its own, for suspensions that
+// do not come from a report.
+func (c *ApiConfig) suspend(ctx context.Context, userId uuid.UUID) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = suspendUser(ctx, c.DBQueries.WithTx(tx), userId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *ApiConfig) deactivateUser(ctx context.Context, userId uuid.UUID) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.UpdateUserDeactivated(ctx, userId); err != nil {
+		return err
+	}
+	if err = queries.DeleteRefreshTokensFromUser(
+		ctx,
+		uuid.NullUUID{UUID: userId, Valid: true},
+	); err != nil {
+		return err
+	}
+	if err = queries.DeletePersonalAccessTokensFromUser(ctx, userId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *ApiConfig) eraseUser(ctx context.Context, user database.User) (database.ErasureReceipt, error) {
+	var err error
+	var tx *sql.Tx
+	var erased int64
+	var receipt database.ErasureReceipt
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return receipt, err
+	}
+	defer tx.Rollback()
+	queries, userId := c.DBQueries.WithTx(tx), uuid.NullUUID{UUID: user.ID, Valid: true}
+	if err = queries.DeleteRefreshTokensFromUser(ctx, userId); err != nil {
+		return receipt, err
+	}
+	switch c.ChirpErasurePolicy {
+	case erasurePolicyAnonymise:
+		erased, err = queries.UpdateChirpsAnonymised(ctx, userId)
+	case erasurePolicyDelete:
+		erased, err = queries.DeleteChirpsFromUser(ctx, userId)
+	default:
+		err = ValidateErasurePolicy(c.ChirpErasurePolicy)
+	}
+	if err != nil {
+		return receipt, err
+	}
+	if err = queries.DeleteUser(ctx, user.ID); err != nil {
+		return receipt, err
+	}
+	// keyed so that the receipt can be matched to an address someone asks
+	// about, but not turned back into one with a dictionary
+	if receipt, err = queries.CreateErasureReceipt(
+		ctx,
+		database.CreateErasureReceiptParams{
+			UserID:       user.ID,
+			EmailHash:    auth.MakeSignature(strings.ToLower(user.Email), c.Secret),
+			Policy:       c.ChirpErasurePolicy,
+			ChirpsErased: erased,
+		},
+	); err != nil {
+		return receipt, err
+	}
+	return receipt, tx.Commit()
+}
+
+// logIn reactivates a deactivated account and hands out a fresh access and
+// refresh token pair, once every credential check has passed.
+func (c *ApiConfig) logIn(ctx context.Context, user database.User,
+	client sessionClient) (database.User, string, string, error) {
+	var err error
+	var token, refreshToken string
+	var session database.RefreshToken
+	if user.DeactivatedAt.Valid {
+		if user, err = c.DBQueries.UpdateUserReactivated(ctx, user.ID); err != nil {
+			return user, empty, empty, err
+		}
+	}
+	if refreshToken, session, err = createRefreshToken(ctx, c.DBQueries, user.ID, client, oauthGrant{}); err != nil {
+		return user, empty, empty, err
+	}
+	if token, err = c.makeAccessToken(user, session); err != nil {
+		return user, empty, empty, err
+	}
+	return user, token, refreshToken, nil
+}
+
+func isUserActive(user database.User) bool {
+	return !user.SuspendedAt.Valid && !user.DeactivatedAt.Valid
+}
@@ -0,0 +1,114 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestValidateErasurePolicy(t *testing.T) {
+	tests := map[string]bool{
+		erasurePolicyDelete:    true,
+		erasurePolicyAnonymise: true,
+		"anonymize":            false,
+		empty:                  false,
+	}
+	for input, want := range tests {
+		if output := ValidateErasurePolicy(input) == nil; output != want {
+			t.Errorf(
+				"ValidateErasurePolicy(\"%s\") == nil = (%t), want (%t)",
+				input, output, want,
+			)
+		}
+	}
+}
+
+func TestEraseUser(t *testing.T) {
+	user := database.User{ID: uuid.New(), Email: "Walt@Example.com"}
+	receipt := testResult{
+		columns: []string{"id", "created_at", "user_id", "email_hash", "policy", "chirps_erased"},
+		rows:    [][]driver.Value{{uuid.NewString(), time.Now(), user.ID.String(), "hash", "delete", int64(3)}},
+	}
+	tests := map[string][]string{
+		erasurePolicyDelete:    {"DeleteRefreshTokensFromUser", "DeleteChirpsFromUser", "DeleteUser", "CreateErasureReceipt"},
+		erasurePolicyAnonymise: {"DeleteRefreshTokensFromUser", "UpdateChirpsAnonymised", "DeleteUser", "CreateErasureReceipt"},
+	}
+	for input, want := range tests {
+		db, testDb := newTestDb(map[string]testResult{
+			"DeleteChirpsFromUser":   {affected: 3},
+			"UpdateChirpsAnonymised": {affected: 3},
+			"CreateErasureReceipt":   receipt,
+		})
+		config := ApiConfig{DB: db, DBQueries: database.New(db), Secret: "secret", ChirpErasurePolicy: input}
+		if _, err := config.eraseUser(context.Background(), user); err != nil || !testDb.committed {
+			t.Errorf("eraseUser() with %s = (%v, committed %t), want (nil, committed)", input, err, testDb.committed)
+		}
+		if output := testDb.ran(); !slices.Equal(output, want) {
+			t.Errorf("eraseUser() with %s ran (%v), want (%v)", input, output, want)
+		}
+		args := testDb.args("CreateErasureReceipt")
+		plainHash := sha256.Sum256([]byte(strings.ToLower(user.Email)))
+		if len(args) != 4 || args[1] != auth.MakeSignature("walt@example.com", config.Secret) ||
+			args[1] == hex.EncodeToString(plainHash[:]) || args[2] != input || args[3] != int64(3) {
+			t.Errorf("eraseUser() with %s stored receipt (%v)", input, args)
+		}
+	}
+
+	db, testDb := newTestDb(map[string]testResult{"DeleteUser": {err: errors.New("boom")}})
+	config := ApiConfig{DB: db, DBQueries: database.New(db), ChirpErasurePolicy: erasurePolicyDelete}
+	if _, err := config.eraseUser(context.Background(), user); err == nil || testDb.committed ||
+		slices.Contains(testDb.ran(), "CreateErasureReceipt") {
+		t.Errorf("eraseUser() after a failed delete = (%v, committed %t), want (error, rolled back)", err, testDb.committed)
+	}
+
+	db, testDb = newTestDb(nil)
+	config = ApiConfig{DB: db, DBQueries: database.New(db), ChirpErasurePolicy: "anonymize"}
+	if _, err := config.eraseUser(context.Background(), user); err == nil || testDb.committed {
+		t.Errorf("eraseUser() with an unknown policy = (%v, committed %t), want (error, rolled back)", err, testDb.committed)
+	}
+}
+
+func TestSuspendRevokesPersonalAccessTokens(t *testing.T) {
+	now, userId := time.Now(), uuid.New()
+	token, err := auth.MakePersonalAccessToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := map[string]testResult{
+		"GetPersonalAccessTokenFromHash": {
+			columns: []string{
+				"id", "created_at", "updated_at", "user_id", "name", "token_hash", "scopes",
+				"expires_at", "last_used_at", "revoked_at",
+			},
+			rows: [][]driver.Value{{
+				uuid.NewString(), now, now, userId.String(), "bot", auth.HashToken(token), scopeChirpsWrite,
+				nil, nil, nil,
+			}},
+		},
+	}
+	db, testDb := newTestDb(results)
+	config := ApiConfig{DB: db, DBQueries: database.New(db)}
+	if _, err = config.validateToken(context.Background(), token, scopeChirpsWrite); err != nil {
+		t.Fatalf("validateToken() before suspension = (%v), want (nil)", err)
+	}
+	if err = config.suspend(context.Background(), userId); err != nil || !testDb.committed {
+		t.Fatalf("suspend() = (%v, committed %t), want (nil, committed true)", err, testDb.committed)
+	}
+	// revoked tokens are no longer found by their hash
+	if slices.Contains(testDb.ran(), "DeletePersonalAccessTokensFromUser") {
+		delete(results, "GetPersonalAccessTokenFromHash")
+	}
+	if _, err = config.validateToken(context.Background(), token, scopeChirpsWrite); err == nil {
+		t.Errorf("validateToken() after suspension = (nil), want (error) after (%v)", testDb.ran())
+	}
+}
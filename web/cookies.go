@@ -0,0 +1,107 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/mamatb/Chirpy/auth"
+)
+
+type cookieSessionKey struct{}
+
+// MiddleCookieSessions lets browsers authenticate with the cookies set at
+// login instead of an Authorization header. Browsers send cookies along with
+// requests started by any site, so requests that change anything must also
+// echo the CSRF cookie in a header, which only pages on Chirpy can read.
+func (c *ApiConfig) MiddleCookieSessions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.CookieSessions || len(r.Header.Get(headerAuthorization)) > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		access, accessErr := r.Cookie(cookieAccessToken)
+		_, refreshErr := r.Cookie(cookieRefreshToken)
+		if accessErr != nil && refreshErr != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !isSafeMethod(r.Method) && !isValidCsrfToken(r) {
+			respJsonForbidden(w, r, errorInvalidCsrfToken)
+			return
+		}
+		r = r.WithContext(context.WithValue(r.Context(), cookieSessionKey{}, true))
+		if accessErr == nil {
+			r.Header = r.Header.Clone()
+			r.Header.Set(headerAuthorization, authorizationBearer+space+access.Value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// setSessionCookies hands the tokens to the browser where scripts cannot
+// read them, along with a fresh CSRF token that they can. The access token is
+// set once for the API and once for the admin endpoints, and nowhere else, so
+// forms posted to other pages like the OAuth consent one never carry it.
+func setSessionCookies(w http.ResponseWriter, token string, refreshToken string) error {
+	csrfToken, err := auth.MakeToken()
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, newSessionCookie(cookieAccessToken, token, cookiePathApi, accessTokenExpiry, true))
+	http.SetCookie(w, newSessionCookie(cookieAccessToken, token, cookiePathAdmin, accessTokenExpiry, true))
+	http.SetCookie(w, newSessionCookie(cookieRefreshToken, refreshToken, cookiePathApi, refreshTokenExpiry, true))
+	http.SetCookie(w, newSessionCookie(cookieCsrfToken, csrfToken, cookiePathRoot, refreshTokenExpiry, false))
+	return nil
+}
+
+func clearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, newSessionCookie(cookieAccessToken, empty, cookiePathApi, -1, true))
+	http.SetCookie(w, newSessionCookie(cookieAccessToken, empty, cookiePathAdmin, -1, true))
+	http.SetCookie(w, newSessionCookie(cookieRefreshToken, empty, cookiePathApi, -1, true))
+	http.SetCookie(w, newSessionCookie(cookieCsrfToken, empty, cookiePathRoot, -1, false))
+}
+
+func newSessionCookie(name string, value string, path string, maxAge time.Duration, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: httpOnly,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// isCookieSession reports whether the request was authenticated with
+// session cookies, so that new tokens go back the same way.
+func isCookieSession(r *http.Request) bool {
+	cookieSession, _ := r.Context().Value(cookieSessionKey{}).(bool)
+	return cookieSession
+}
+
+// getRefreshToken takes the refresh token from its cookie in a cookie
+// session, and from the Authorization header otherwise.
+func getRefreshToken(r *http.Request) (string, error) {
+	if !isCookieSession(r) {
+		return auth.GetBearerToken(r.Header)
+	}
+	cookie, err := r.Cookie(cookieRefreshToken)
+	if err != nil {
+		return empty, err
+	}
+	return cookie.Value, nil
+}
+
+func isValidCsrfToken(r *http.Request) bool {
+	cookie, err := r.Cookie(cookieCsrfToken)
+	header := r.Header.Get(headerCsrfToken)
+	return err == nil && len(cookie.Value) > 0 &&
+		subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(header)) == 1
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+type cookieSessionInput struct {
+	method        string
+	authorization string
+	cookie        string
+	csrfToken     string
+}
+
+type cookieSessionOutput struct {
+	status        int
+	authorization string
+	cookieSession bool
+}
+
+func TestMiddleCookieSessions(t *testing.T) {
+	var output cookieSessionOutput
+	config := ApiConfig{CookieSessions: true}
+	handler := config.MiddleCookieSessions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		output.authorization, output.cookieSession = r.Header.Get(headerAuthorization), isCookieSession(r)
+	}))
+	tests := map[cookieSessionInput]cookieSessionOutput{
+		{http.MethodGet, empty, "chirpy_access=jwt", empty}:                      {http.StatusOK, "Bearer jwt", true},
+		{http.MethodPost, empty, "chirpy_access=jwt; chirpy_csrf=csrf", empty}:   {http.StatusForbidden, empty, false},
+		{http.MethodDelete, empty, "chirpy_access=jwt; chirpy_csrf=csrf", "bad"}: {http.StatusForbidden, empty, false},
+		{http.MethodPost, empty, "chirpy_access=jwt; chirpy_csrf=csrf", "csrf"}:  {http.StatusOK, "Bearer jwt", true},
+		{http.MethodPost, empty, "chirpy_refresh=refresh; chirpy_csrf=csrf", "csrf"}: {
+			http.StatusOK, empty, true,
+		},
+		{http.MethodPost, "Bearer header", "chirpy_access=jwt", empty}: {http.StatusOK, "Bearer header", false},
+		{http.MethodPost, empty, empty, empty}:                         {http.StatusOK, empty, false},
+	}
+	for input, want := range tests {
+		output = cookieSessionOutput{}
+		request := httptest.NewRequest(input.method, "/api/chirps", nil)
+		request.Header.Set(headerAuthorization, input.authorization)
+		request.Header.Set("Cookie", input.cookie)
+		request.Header.Set(headerCsrfToken, input.csrfToken)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if output.status = recorder.Code; output != want {
+			t.Errorf("MiddleCookieSessions(%+v) = (%+v), want (%+v)", input, output, want)
+		}
+	}
+}
+
+func TestSetSessionCookies(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	if err := setSessionCookies(recorder, "jwt", "refresh"); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := &url.URL{Scheme: "https", Host: "chirpy.example", Path: "/api/login"}
+	jar.SetCookies(site, recorder.Result().Cookies())
+	for _, cookie := range recorder.Result().Cookies() {
+		if !cookie.Secure || cookie.SameSite != http.SameSiteStrictMode ||
+			cookie.HttpOnly != (cookie.Name != cookieCsrfToken) || len(cookie.Value) == 0 {
+			t.Errorf("setSessionCookies() %s = (%+v)", cookie.Name, cookie)
+		}
+	}
+	tests := map[string]string{
+		"/api/chirps":      "chirpy_access chirpy_refresh chirpy_csrf",
+		"/admin/metrics":   "chirpy_access chirpy_csrf",
+		"/oauth/authorize": "chirpy_csrf",
+		"/app/":            "chirpy_csrf",
+	}
+	for input, want := range tests {
+		var names []string
+		for _, cookie := range jar.Cookies(site.ResolveReference(&url.URL{Path: input})) {
+			names = append(names, cookie.Name)
+		}
+		if output := strings.Join(names, space); output != want {
+			t.Errorf("setSessionCookies() sent to %s = (%s), want (%s)", input, output, want)
+		}
+	}
+}
+
+// TestOauthConsentCookieSessions posts the consent form the way a browser
+// logged in with cookie sessions would, with whatever cookies it sends there.
+func TestOauthConsentCookieSessions(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	if err := setSessionCookies(recorder, "jwt", "refresh"); err != nil {
+		t.Fatal(err)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site := &url.URL{Scheme: "https", Host: "chirpy.example", Path: "/api/login"}
+	jar.SetCookies(site, recorder.Result().Cookies())
+	config := ApiConfig{CookieSessions: true}
+	handler := config.MiddleCookieSessions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	form := url.Values{"decision": {"deny"}, "client_id": {uuid.NewString()}}
+	request := httptest.NewRequest(http.MethodPost, "/oauth/authorize", strings.NewReader(form.Encode()))
+	request.Header.Set(headerContentType, "application/x-www-form-urlencoded")
+	for _, cookie := range jar.Cookies(site.ResolveReference(&url.URL{Path: "/oauth/authorize"})) {
+		request.AddCookie(cookie)
+	}
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if output := recorder.Code; output != http.StatusOK {
+		t.Errorf("POST /oauth/authorize with session cookies = (%d), want (%d)", output, http.StatusOK)
+	}
+}
+
+func TestHandleLogInCookieSessions(t *testing.T) {
+	now := time.Now()
+	session := testResult{
+		columns: []string{
+			"token_hash", "created_at", "updated_at", "user_id", "expires_at", "revoked_at", "family_id",
+			"rotated_at", "started_at", "last_used_at", "user_agent", "ip_address", "device", "client_id",
+			"scopes",
+		},
+		rows: [][]driver.Value{{
+			"hash", now, now, uuid.NewString(), now, nil, uuid.NewString(),
+			nil, now, now, empty, empty, empty, nil,
+			empty,
+		}},
+	}
+	tests := map[bool]bool{
+		false: true,
+		true:  false,
+	}
+	for input, want := range tests {
+		db, _ := newTestDb(map[string]testResult{"CreateRefreshToken": session})
+		config := ApiConfig{
+			DBQueries:      database.New(db),
+			Keys:           auth.NewKeySet(auth.AlgorithmHs256, "secret", true),
+			CookieSessions: input,
+		}
+		body := jsonUser{}
+		recorder := httptest.NewRecorder()
+		handleLogIn(recorder, httptest.NewRequest(http.MethodPost, "/api/login", nil), &config,
+			database.User{ID: uuid.New()}, errorInvalidEmailPassword)
+		err := json.NewDecoder(recorder.Body).Decode(&body)
+		if output := len(body.Token) > 0 && len(body.RefreshToken) > 0; err != nil || output != want ||
+			recorder.Code != http.StatusOK || (len(recorder.Result().Cookies()) > 0) != input {
+			t.Errorf(
+				"handleLogIn() with cookie sessions %t = (%d, tokens in body %t, %v), want (%d, %t)",
+				input, recorder.Code, output, err, http.StatusOK, want,
+			)
+		}
+	}
+}
@@ -1,65 +1,495 @@
 package web
 
 import (
+	"database/sql"
+	"net/http"
+	"regexp"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
 	"github.com/mamatb/Chirpy/database"
 )
 
 const (
-	daysInMonth = 30
-	hoursInDay  = 24
-
-	contentTypeHtml           = "text/html; charset=utf-8"
-	contentTypeJson           = "application/json; charset=utf-8"
-	contentTypePlain          = "text/plain; charset=utf-8"
-	cwd                       = "."
-	empty                     = ""
-	platformDev               = "dev"
-	errorChirpTooLong         = "Chirp is too long"
-	errorInvalidEmailPassword = "Invalid email or password"
-	errorInvalidToken         = "Invalid token"
-	errorInvalidRefreshToken  = "Invalid refresh token"
-	errorMissingToken         = "Missing token"
-	errorMissingRefreshToken  = "Missing refresh token"
-	errorSomethingWentWrong   = "Something went wrong"
-	headerContentType         = "Content-Type"
-	httpForbiddenPlain        = "FORBIDDEN"
-	httpNotFoundPlain         = "NOT FOUND"
-	httpOkPlain               = "OK"
-	httpUnauthorizedPlain     = "UNAUTHORIZED"
-	orderDesc                 = "desc"
-	polkaEventUserUpgraded    = "user.upgraded"
-	profanityReplacement      = "****"
-	space                     = " "
+	accessTokenExpiry        = time.Hour
+	avatarMaxBytes           = 1024 * 1024
+	avatarMaxDimension       = 1024
+	chirpUrlLength           = 23
+	daysInMonth              = 30
+	exportLimit              = 3
+	exportQueueLength        = 64
+	exportRetention          = 7 * 24 * time.Hour
+	exportRetryAfter         = time.Minute
+	exportUrlExpiry          = 24 * time.Hour
+	exportWindow             = 24 * time.Hour
+	hoursInDay               = 24
+	importMaxBytes           = 10 * 1024 * 1024
+	importMaxLineBytes       = 64 * 1024
+	linkCodeLength           = 6
+	linkFetchTimeout         = 5 * time.Second
+	linkMaxBytes             = 512 * 1024
+	linkMaxRedirects         = 3
+	linkQueueLength          = 256
+	loginBackoffBase         = time.Minute
+	loginBackoffDoublings    = 6
+	loginBackoffMax          = time.Hour
+	loginEmailFailures       = 5
+	loginFailureWindow       = 24 * time.Hour
+	loginIpAddressFailures   = 20
+	loginMfaFailures         = 5
+	magicLinkExpiry          = 15 * time.Minute
+	magicLinkLimit           = 3
+	magicLinkWindow          = time.Hour
+	mfaChallengeExpiry       = 5 * time.Minute
+	mfaMaxAttempts           = 5
+	oauthClientNameLength    = 100
+	oauthCodeExpiry          = 10 * time.Minute
+	oauthMaxRedirectUris     = 10
+	oidcLoginExpiry          = 10 * time.Minute
+	passwordResetExpiry      = time.Hour
+	passwordResetLimit       = 3
+	passwordResetWindow      = time.Hour
+	profileBioLength         = 160
+	profileDisplayNameLength = 50
+	profileLocationLength    = 30
+	qrCodeSize               = 256
+	recoveryCodeGroup        = 4
+	recoveryCodeLength       = 10
+	recoveryCodesCount       = 10
+	refreshTokenExpiry       = 2 * daysInMonth * hoursInDay * time.Hour
+	reportDetailsLength      = 1000
+	sessionUserAgentLength   = 512
+	spamMaxLinks             = 2
+	spamMaxMentions          = 3
+	tokenMaxExpiryDays       = 365
+	tokenNameLength          = 100
+	verificationExpiry       = 24 * time.Hour
+	verificationLimit        = 3
+	verificationWindow       = time.Hour
+
+	spamWeightLink            = 0.4
+	spamWeightLinkDensity     = 0.6
+	spamWeightMention         = 0.1
+	spamWeightMentionRepeated = 0.3
+	spamWeightVelocity        = 0.6
+
+	authorizationBearer          = "Bearer"
+	avatarPath                   = "/api/users/%s/avatar?v=%d"
+	cacheControlAvatar           = "public, max-age=86400"
+	cacheControlJwks             = "public, max-age=600"
+	cacheControlNoStore          = "no-store"
+	chirpStatusHidden            = "hidden"
+	chirpStatusModeration        = "moderation"
+	chirpStatusPublished         = "published"
+	cookieAccessToken            = "chirpy_access"
+	cookieCsrfToken              = "chirpy_csrf"
+	cookiePathAdmin              = "/admin/"
+	cookiePathApi                = "/api/"
+	cookiePathRoot               = "/"
+	cookieRefreshToken           = "chirpy_refresh"
+	contentTypeHtml              = "text/html; charset=utf-8"
+	contentTypeCsv               = "text/csv"
+	contentTypeJson              = "application/json; charset=utf-8"
+	contentTypeMergePatch        = "application/merge-patch+json"
+	contentTypePlain             = "text/plain; charset=utf-8"
+	contentTypeNdjson            = "application/x-ndjson"
+	contentTypeZip               = "application/zip"
+	cspFrameAncestorsNone        = "frame-ancestors 'none'"
+	cwd                          = "."
+	dataUriPng                   = "data:image/png;base64,"
+	deprecationTrue              = "true"
+	empty                        = ""
+	platformDev                  = "dev"
+	erasurePolicyAnonymise       = "anonymise"
+	erasurePolicyDelete          = "delete"
+	exportFileChirps             = "chirps.json"
+	exportFileChirpsCsv          = "chirps.csv"
+	exportFileProfile            = "profile.json"
+	exportFileSessions           = "sessions.json"
+	exportFileName               = "attachment; filename=\"chirpy-export-%s.zip\""
+	exportIndent                 = "  "
+	exportStatusReady            = "ready"
+	errorAccountInactive         = "Account is inactive"
+	errorAccountSuspended        = "Account is suspended"
+	errorChirpDuplicate          = "Chirp is a duplicate"
+	errorChirpEmpty              = "Chirp is empty"
+	errorChirpTooLong            = "Chirp is too long"
+	errorEmailAlreadyVerified    = "Email is already verified"
+	errorEmailUnverified         = "Email is not verified"
+	errorExportLinkExpired       = "export link has expired"
+	errorExportQueueFull         = "Too many exports in progress, try again later"
+	errorInvalidCreatedAt        = "Invalid created_at"
+	errorInvalidImportFormat     = "Invalid import format"
+	errorInvalidImportHeader     = "Invalid import header"
+	errorInvalidRecord           = "Invalid record"
+	errorRecordTooLong           = "Record is too long"
+	errorInvalidRedirectUri      = "Invalid redirect uri"
+	errorInvalidCsrfToken        = "Invalid CSRF token"
+	errorInvalidAvatar           = "Invalid avatar"
+	errorInvalidClientName       = "Invalid client name"
+	errorInvalidBio              = "Invalid bio"
+	errorInvalidDisplayName      = "Invalid display name"
+	errorInvalidLocation         = "Invalid location"
+	errorInvalidUsername         = "Invalid username"
+	errorInvalidEmailPassword    = "Invalid email or password"
+	errorInvalidErasurePolicy    = "invalid chirp erasure policy %q"
+	errorLinkForbiddenAddress    = "link resolves to a forbidden address"
+	errorLinkNotHtml             = "link is not an html document"
+	errorLinkTooManyRedirects    = "link has too many redirects"
+	errorInvalidPatch            = "Invalid patch"
+	errorInvalidPassword         = "Invalid password"
+	errorInvalidMfaCode          = "Invalid MFA code"
+	errorInvalidMfaToken         = "Invalid MFA token"
+	errorInvalidModerationAction = "Invalid moderation action"
+	errorInvalidProfanity        = "Invalid profanity"
+	errorInvalidRole             = "Invalid role"
+	errorInvalidReport           = "Invalid report"
+	errorInsufficientScope       = "Token lacks the required scope"
+	errorInvalidScopes           = "Invalid scopes"
+	errorInvalidToken            = "Invalid token"
+	errorInvalidTokenExpiry      = "Invalid token expiry"
+	errorInvalidTokenName        = "Invalid token name"
+	errorInvalidRefreshToken     = "Invalid refresh token"
+	errorMfaAlreadyEnabled       = "MFA is already enabled"
+	errorMfaNotEnabled           = "MFA is not enabled"
+	errorMissingToken            = "Missing token"
+	errorMissingRefreshToken     = "Missing refresh token"
+	errorOauthAccessDenied       = "The user denied access"
+	errorOauthClientNotFound     = "Client not found"
+	errorOauthCodeChallenge      = "An S256 code_challenge is required"
+	errorOauthGrantType          = "Unsupported grant_type"
+	errorOauthInvalidCode        = "Invalid authorization code"
+	errorOauthResponseType       = "Unsupported response_type"
+	errorOidcNoAccount           = "No verified account matches this identity"
+	errorRefreshTokenReused      = "Refresh token reused, every session from that login was revoked"
+	errorSessionNotFound         = "Session not found"
+	errorSomethingWentWrong      = "Something went wrong"
+	errorTokenNotFound           = "Token not found"
+	errorTooManyLoginAttempts    = "Too many failed login attempts, try again later"
+	errorTooManyExports          = "Too many exports requested, try again later"
+	errorTooManyRequests         = "Too many requests"
+	errorUsernameTaken           = "Username is taken"
+	headerAuthorization          = "Authorization"
+	headerCacheControl           = "Cache-Control"
+	headerContentDisposition     = "Content-Disposition"
+	headerContentSecurityPolicy  = "Content-Security-Policy"
+	headerContentType            = "Content-Type"
+	headerCsrfToken              = "X-CSRF-Token"
+	headerDeprecation            = "Deprecation"
+	headerFrameOptions           = "X-Frame-Options"
+	headerRetryAfter             = "Retry-After"
+	headerUserAgent              = "User-Agent"
+	headerWwwAuthenticate        = "WWW-Authenticate"
+	frameOptionsDeny             = "DENY"
+	httpForbiddenPlain           = "FORBIDDEN"
+	httpNotFoundPlain            = "NOT FOUND"
+	httpOkPlain                  = "OK"
+	httpUnauthorizedPlain        = "UNAUTHORIZED"
+	importColumnBody             = "body"
+	importColumnCreatedAt        = "created_at"
+	importFormatCsv              = "csv"
+	importFormatNdjson           = "ndjson"
+	jsonNull                     = "null"
+	linkPath                     = "/l/"
+	magicLinkCallbackPath        = "/api/login/magic/callback"
+	mailMagicLinkBody            = "Log in to Chirpy within the next 15 minutes by opening this link on the device you asked from:\n\n%s\n\nIf it was not you, you can ignore this message.\n"
+	mailMagicLinkSubject         = "Your Chirpy login link"
+	mailVerificationBody         = "Confirm your Chirpy email address by opening this link:\n\n%s\n\nIf you did not sign up for Chirpy you can ignore this message.\n"
+	mailVerificationSubject      = "Verify your Chirpy email address"
+	mailPasswordResetBody        = "Someone asked to reset your Chirpy password. Choose a new one within the hour by opening this link:\n\n%s\n\nIf it was not you, you can ignore this message and your password stays the same.\n"
+	mailPasswordResetSubject     = "Reset your Chirpy password"
+	mfaIssuer                    = "Chirpy"
+	mediaTypeJson                = "application/json"
+	moderationActionDismiss      = "dismiss"
+	moderationActionHide         = "hide"
+	moderationActionSuspend      = "suspend"
+	linkUserAgent                = "Chirpy/1.0 (link preview)"
+	oauthChallengeS256           = "S256"
+	oauthDecisionApprove         = "approve"
+	oauthErrorAccessDenied       = "access_denied"
+	oauthErrorGrantType          = "unsupported_grant_type"
+	oauthErrorInvalidClient      = "invalid_client"
+	oauthErrorInvalidGrant       = "invalid_grant"
+	oauthErrorInvalidRequest     = "invalid_request"
+	oauthErrorInvalidScope       = "invalid_scope"
+	oauthErrorResponseType       = "unsupported_response_type"
+	oauthGrantAuthorizationCode  = "authorization_code"
+	oauthGrantRefreshToken       = "refresh_token"
+	oauthParamChallengeMethod    = "code_challenge_method"
+	oauthParamClientId           = "client_id"
+	oauthParamClientSecret       = "client_secret"
+	oauthParamCode               = "code"
+	oauthParamCodeChallenge      = "code_challenge"
+	oauthParamCodeVerifier       = "code_verifier"
+	oauthParamDecision           = "decision"
+	oauthParamEmail              = "email"
+	oauthParamError              = "error"
+	oauthParamErrorDescription   = "error_description"
+	oauthParamGrantType          = "grant_type"
+	oauthParamMfaCode            = "mfa_code"
+	oauthParamPassword           = "password"
+	oauthParamRedirectUri        = "redirect_uri"
+	oauthParamRefreshToken       = "refresh_token"
+	oauthParamResponseType       = "response_type"
+	oauthParamScope              = "scope"
+	oauthParamState              = "state"
+	oauthParamToken              = "token"
+	oauthResponseTypeCode        = "code"
+	oauthTokenTypeAccess         = "access_token"
+	oauthTokenTypeBearer         = "Bearer"
+	oauthTokenTypeRefresh        = "refresh_token"
+	orderDesc                    = "desc"
+	passwordResetPath            = "/app/reset-password.html"
+	patternCodeChallenge         = `^[-_0-9A-Za-z]{43}$`
+	patternCodeVerifier          = `^[-._~0-9A-Za-z]{43,128}$`
+	patternHtmlAttribute         = `([-:A-Za-z]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`
+	patternMention               = `@\w+`
+	patternMetaTag               = `(?i)<meta\s[^>]*>`
+	patternTitleTag              = `(?is)<title[^>]*>(.*?)</title>`
+	patternUsername              = `^[a-z0-9_]{3,30}$`
+	patternUrl                   = `https?://[^\s]+`
+	patternWord                  = `\S+`
+	polkaEventUserUpgraded       = "user.upgraded"
+	profanityModeSubstring       = "substring"
+	profanityModeWord            = "word"
+	processorDuplicate           = "duplicate"
+	processorLength              = "length"
+	processorLinks               = "links"
+	processorNormalise           = "normalise"
+	processorProfanity           = "profanity"
+	processorSpam                = "spam"
+	profanityReplacement         = "****"
+	queryExpires                 = "expires"
+	querySignature               = "signature"
+	queryToken                   = "token"
+	reasonDuplicate              = "duplicate"
+	reasonEmpty                  = "empty"
+	reasonTooLong                = "too_long"
+	reportDetailsSpam            = "Automatically flagged with spam score %.2f"
+	reportReasonAbuse            = "abuse"
+	reportReasonHarassment       = "harassment"
+	reportReasonOther            = "other"
+	reportReasonSpam             = "spam"
+	reportStatusOpen             = "open"
+	roleAdmin                    = "admin"
+	roleModerator                = "moderator"
+	roleUser                     = "user"
+	scopeChirpsRead              = "chirps:read"
+	scopeChirpsWrite             = "chirps:write"
+	scopeProfileWrite            = "profile:write"
+	sessionDeviceUnknown         = "Unknown"
+	space                        = " "
+	urlTrailingPunctuation       = ".,:;!?'\""
+	verifyPath                   = "/api/verify"
+	wwwAuthenticateBasic         = "Basic realm=\"chirpy\""
+	zeroWidthJoiner              = '\u200d'
+)
+
+var (
+	avatarContentTypes = []string{
+		"image/gif",
+		"image/jpeg",
+		"image/png",
+	}
+	exportChirpsCsvHeader = []string{"id", "created_at", "updated_at", "status", "body"}
+	moderationActions     = []string{
+		moderationActionDismiss,
+		moderationActionHide,
+		moderationActionSuspend,
+	}
+	oauthScopeDescriptions = map[string]string{
+		scopeChirpsRead:   "Read chirps",
+		scopeChirpsWrite:  "Post, delete and report chirps on your behalf",
+		scopeProfileWrite: "Edit your profile and avatar",
+	}
+	personalAccessTokenScopes = []string{
+		scopeChirpsRead,
+		scopeChirpsWrite,
+		scopeProfileWrite,
+	}
+	reportReasons = []string{
+		reportReasonAbuse,
+		reportReasonHarassment,
+		reportReasonOther,
+		reportReasonSpam,
+	}
+	reservedUsernames = []string{
+		"admin",
+		"me",
+	}
+	roleRanks = map[string]int{
+		roleUser:      1,
+		roleModerator: 2,
+		roleAdmin:     3,
+	}
+	sessionDevices = []struct {
+		marker string
+		name   string
+	}{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"CrOS", "ChromeOS"},
+		{"Windows", "Windows"},
+		{"Macintosh", "Mac"},
+		{"Linux", "Linux"},
+	}
+
+	regexCodeChallenge = regexp.MustCompile(patternCodeChallenge)
+	regexCodeVerifier  = regexp.MustCompile(patternCodeVerifier)
+	regexHtmlAttribute = regexp.MustCompile(patternHtmlAttribute)
+	regexMention       = regexp.MustCompile(patternMention)
+	regexMetaTag       = regexp.MustCompile(patternMetaTag)
+	regexTitleTag      = regexp.MustCompile(patternTitleTag)
+	regexUrl           = regexp.MustCompile(patternUrl)
+	regexUsername      = regexp.MustCompile(patternUsername)
+	regexWord          = regexp.MustCompile(patternWord)
 )
 
 type ApiConfig struct {
-	Platform       string
-	PolkaKey       string
-	Secret         string
-	DBQueries      *database.Queries
-	FileserverHits atomic.Int32
+	Platform                 string
+	PolkaKey                 string
+	Secret                   string
+	BaseUrl                  string
+	ChirpMaxLength           int
+	ChirpMaxLengthRed        int
+	DuplicateWindow          time.Duration
+	DeactivationPeriod       time.Duration
+	ChirpErasurePolicy       string
+	SpamThreshold            float64
+	SpamNewAccountAge        time.Duration
+	SpamNewAccountRate       int64
+	RequireEmailVerification bool
+	CookieSessions           bool
+	EncryptionKey            []byte
+	JwtKeyRotation           time.Duration
+	JwtKeyOverlap            time.Duration
+	Keys                     *auth.KeySet
+	Oidc                     *auth.OidcProvider
+	Mailer                   Mailer
+	DB                       *sql.DB
+	DBQueries                *database.Queries
+	FileserverHits           atomic.Int32
+	profanities              profanityFilter
+	chirpProcessors          []ChirpProcessor
+	linkClient               *http.Client
+	linkPreviews             chan database.ChirpLink
+	dataExports              chan database.DataExport
 }
 
 type jsonError struct {
 	Error string `json:"error"`
 }
 
+type jsonErrorChirpRejected struct {
+	Error   string           `json:"error"`
+	Reasons []ChirpRejection `json:"reasons"`
+}
+
 type jsonUser struct {
-	Id           uuid.UUID `json:"id"`
+	Id            uuid.UUID `json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Email         string    `json:"email"`
+	IsChirpyRed   bool      `json:"is_chirpy_red"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"`
+	MfaEnabled    bool      `json:"mfa_enabled"`
+	Username      string    `json:"username"`
+	DisplayName   string    `json:"display_name"`
+	Bio           string    `json:"bio"`
+	Location      string    `json:"location"`
+	AvatarUrl     string    `json:"avatar_url,omitempty"`
+	Token         string    `json:"token,omitempty"`
+	RefreshToken  string    `json:"refresh_token,omitempty"`
+}
+
+type jsonProfile struct {
+	Id          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	Bio         string    `json:"bio"`
+	Location    string    `json:"location"`
+	AvatarUrl   string    `json:"avatar_url,omitempty"`
+	IsChirpyRed bool      `json:"is_chirpy_red"`
+}
+
+type jsonMfaChallenge struct {
+	MfaRequired bool      `json:"mfa_required"`
+	MfaToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type jsonTotpEnrolment struct {
+	Secret     string `json:"secret"`
+	OtpauthUri string `json:"otpauth_uri"`
+	QrCode     string `json:"qr_code"`
+}
+
+type jsonRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type jsonSession struct {
+	Id         uuid.UUID `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IpAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+type jsonPersonalAccessToken struct {
+	Id         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Token      string     `json:"token,omitempty"`
+}
+
+type jsonOauthClient struct {
+	Id           uuid.UUID `json:"client_id"`
 	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
-	Email        string    `json:"email"`
-	IsChirpyRed  bool      `json:"is_chirpy_red"`
-	Token        string    `json:"token,omitempty"`
-	RefreshToken string    `json:"refresh_token,omitempty"`
+	Name         string    `json:"name"`
+	RedirectUris []string  `json:"redirect_uris"`
+	Confidential bool      `json:"confidential"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+}
+
+type jsonOauthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+type jsonOauthIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientId  string `json:"client_id,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	Issuer    string `json:"iss,omitempty"`
+}
+
+type jsonOauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
 }
 
 type jsonToken struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 type jsonChirp struct {
@@ -68,4 +498,91 @@ type jsonChirp struct {
 	UpdatedAt time.Time     `json:"updated_at"`
 	Body      string        `json:"body"`
 	UserId    uuid.NullUUID `json:"user_id"`
+	Status    string        `json:"status"`
+	Links     []jsonLink    `json:"links"`
+}
+
+type jsonLink struct {
+	Url      string           `json:"url"`
+	ShortUrl string           `json:"short_url"`
+	Preview  *jsonLinkPreview `json:"preview,omitempty"`
+}
+
+type jsonLinkPreview struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageUrl    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+type jsonProfanity struct {
+	Word      string    `json:"word"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Mode      string    `json:"mode"`
+}
+
+type jsonReport struct {
+	Id         uuid.UUID     `json:"id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+	ChirpId    uuid.UUID     `json:"chirp_id"`
+	ReporterId uuid.NullUUID `json:"reporter_id"`
+	Reason     string        `json:"reason"`
+	Details    string        `json:"details"`
+	Status     string        `json:"status"`
+}
+
+type jsonModerationDecision struct {
+	Id          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ReportId    uuid.NullUUID `json:"report_id"`
+	ChirpId     uuid.NullUUID `json:"chirp_id"`
+	AuthorId    uuid.NullUUID `json:"author_id"`
+	ModeratorId uuid.NullUUID `json:"moderator_id"`
+	Action      string        `json:"action"`
+	Note        string        `json:"note"`
+}
+
+type jsonErasureReceipt struct {
+	Id           uuid.UUID `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UserId       uuid.UUID `json:"user_id"`
+	EmailHash    string    `json:"email_hash"`
+	Policy       string    `json:"policy"`
+	ChirpsErased int64     `json:"chirps_erased"`
+}
+
+type jsonDataExport struct {
+	Id          uuid.UUID `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	DownloadUrl string    `json:"download_url,omitempty"`
+}
+
+type jsonExportProfile struct {
+	Id              uuid.UUID  `json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Email           string     `json:"email"`
+	IsChirpyRed     bool       `json:"is_chirpy_red"`
+	Role            string     `json:"role"`
+	Username        string     `json:"username"`
+	DisplayName     string     `json:"display_name"`
+	Bio             string     `json:"bio"`
+	Location        string     `json:"location"`
+	SuspendedAt     *time.Time `json:"suspended_at,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+}
+
+type jsonExportSession struct {
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Device    string     `json:"device"`
+	UserAgent string     `json:"user_agent"`
+	IpAddress string     `json:"ip_address"`
 }
@@ -0,0 +1,171 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var regexQueryName = regexp.MustCompile(`^-- name: (\w+)`)
+
+// testDb stands in for postgres by answering each sqlc query by its name and
+// recording what ran, so code that runs queries in a transaction is testable.
+type testDb struct {
+	mutex     sync.Mutex
+	results   map[string]testResult
+	queries   []testQuery
+	committed bool
+}
+
+type testResult struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+type testQuery struct {
+	name string
+	args []driver.Value
+}
+
+// testUserResult is a users row as the queries that return one read it.
+func testUserResult(userId uuid.UUID) testResult {
+	now := time.Now()
+	return testResult{
+		columns: []string{
+			"id", "created_at", "updated_at", "email", "hashed_password", "is_chirpy_red",
+			"suspended_at", "role", "deactivated_at", "username", "display_name", "bio",
+			"location", "avatar_updated_at", "email_verified_at", "totp_secret",
+			"totp_enabled_at", "totp_last_step", "token_version",
+		},
+		rows: [][]driver.Value{{
+			userId.String(), now, now, "walt@example.com", "hash", false,
+			nil, roleUser, nil, nil, empty, empty,
+			empty, nil, nil, nil,
+			nil, int64(0), int64(1),
+		}},
+	}
+}
+
+func newTestDb(results map[string]testResult) (*sql.DB, *testDb) {
+	db := &testDb{results: results}
+	return sql.OpenDB(db), db
+}
+
+func (d *testDb) ran() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var names []string
+	for _, query := range d.queries {
+		names = append(names, query.name)
+	}
+	return names
+}
+
+func (d *testDb) args(name string) []driver.Value {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, query := range d.queries {
+		if query.name == name {
+			return query.args
+		}
+	}
+	return nil
+}
+
+func (d *testDb) run(query string, args []driver.NamedValue) testResult {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	name := empty
+	if match := regexQueryName.FindStringSubmatch(query); match != nil {
+		name = match[1]
+	}
+	values := make([]driver.Value, len(args))
+	for argIdx, arg := range args {
+		values[argIdx] = arg.Value
+	}
+	d.queries = append(d.queries, testQuery{name: name, args: values})
+	return d.results[name]
+}
+
+func (d *testDb) Connect(context.Context) (driver.Conn, error) {
+	return testConn{d}, nil
+}
+
+func (d *testDb) Driver() driver.Driver {
+	return nil
+}
+
+type testConn struct {
+	db *testDb
+}
+
+func (c testConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c testConn) Close() error {
+	return nil
+}
+
+func (c testConn) Begin() (driver.Tx, error) {
+	return testTx(c), nil
+}
+
+func (c testConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.db.run(query, args)
+	return driver.RowsAffected(result.affected), result.err
+}
+
+func (c testConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.run(query, args)
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &testRows{columns: result.columns, rows: result.rows}, nil
+}
+
+type testTx struct {
+	db *testDb
+}
+
+func (t testTx) Commit() error {
+	t.db.mutex.Lock()
+	defer t.db.mutex.Unlock()
+	t.db.committed = true
+	return nil
+}
+
+func (t testTx) Rollback() error {
+	return nil
+}
+
+type testRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *testRows) Columns() []string {
+	return r.columns
+}
+
+func (r *testRows) Close() error {
+	return nil
+}
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
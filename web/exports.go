@@ -0,0 +1,260 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+var (
+	errExportLimit     = errors.New(errorTooManyExports)
+	errExportQueueFull = errors.New(errorExportQueueFull)
+)
+
+func (c *ApiConfig) PurgeExpiredExports(ctx context.Context) error {
+	return c.DBQueries.DeleteDataExportsExpired(ctx)
+}
+
+// StartDataExports builds archives on a fixed number of workers, picking up
+// again the exports that were still pending when the server stopped.
+func (c *ApiConfig) StartDataExports(ctx context.Context, workers int) error {
+	var err error
+	var pending []database.DataExport
+	c.dataExports = make(chan database.DataExport, exportQueueLength)
+	for range workers {
+		go func() {
+			for export := range c.dataExports {
+				c.buildDataExport(ctx, export)
+			}
+		}()
+	}
+	if pending, err = c.DBQueries.GetDataExportsPending(ctx); err != nil {
+		return err
+	}
+	for _, export := range pending {
+		if !c.enqueueDataExport(export) {
+			break // the rest are picked up again on next start
+		}
+	}
+	return nil
+}
+
+func (c *ApiConfig) enqueueDataExport(export database.DataExport) bool {
+	select {
+	case c.dataExports <- export:
+		return true
+	default:
+		return false
+	}
+}
+
+// requestDataExport queues an export for the user unless they asked for too
+// many lately, or the workers are too far behind to take one more.
+func (c *ApiConfig) requestDataExport(ctx context.Context, userId uuid.UUID) (database.DataExport, error) {
+	var err error
+	var recent int64
+	var export database.DataExport
+	if recent, err = c.DBQueries.CountDataExportsFromUser(
+		ctx,
+		database.CountDataExportsFromUserParams{
+			UserID:    userId,
+			CreatedAt: time.Now().Add(-exportWindow),
+		},
+	); err != nil {
+		return export, err
+	}
+	if recent >= exportLimit {
+		return export, errExportLimit
+	}
+	if export, err = c.DBQueries.CreateDataExport(
+		ctx,
+		database.CreateDataExportParams{
+			UserID:    userId,
+			ExpiresAt: time.Now().Add(exportRetention),
+		},
+	); err != nil {
+		return export, err
+	}
+	if !c.enqueueDataExport(export) {
+		if err = c.DBQueries.UpdateDataExportFailed(ctx, export.ID); err != nil {
+			return export, err
+		}
+		return export, errExportQueueFull
+	}
+	return export, nil
+}
+
+func (c *ApiConfig) buildDataExport(ctx context.Context, export database.DataExport) {
+	if archive, err := c.makeExportArchive(ctx, export.UserID); err != nil {
+		log.Print(err)
+		if err = c.DBQueries.UpdateDataExportFailed(ctx, export.ID); err != nil {
+			log.Print(err)
+		}
+	} else if err = c.DBQueries.UpdateDataExportReady(
+		ctx,
+		database.UpdateDataExportReadyParams{
+			ID:      export.ID,
+			Archive: archive,
+		},
+	); err != nil {
+		log.Print(err)
+	}
+}
+
+func (c *ApiConfig) makeExportArchive(ctx context.Context, userId uuid.UUID) ([]byte, error) {
+	var err error
+	var user database.User
+	var chirps []database.Chirp
+	var refreshTokens []database.RefreshToken
+	if user, err = c.DBQueries.GetUserFromId(ctx, userId); err != nil {
+		return nil, err
+	}
+	if chirps, err = c.DBQueries.GetChirpsAllFromUser(
+		ctx,
+		uuid.NullUUID{UUID: userId, Valid: true},
+	); err != nil {
+		return nil, err
+	}
+	if refreshTokens, err = c.DBQueries.GetRefreshTokensFromUser(
+		ctx,
+		uuid.NullUUID{UUID: userId, Valid: true},
+	); err != nil {
+		return nil, err
+	}
+	profile := jsonExportProfile{
+		Id:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+		Email:       user.Email,
+		IsChirpyRed: user.IsChirpyRed,
+		Role:        user.Role,
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Location:    user.Location,
+	}
+	if user.SuspendedAt.Valid {
+		profile.SuspendedAt = &user.SuspendedAt.Time
+	}
+	if user.EmailVerifiedAt.Valid {
+		profile.EmailVerifiedAt = &user.EmailVerifiedAt.Time
+	}
+	chirpsJson := []jsonChirp{}
+	for _, chirp := range chirps {
+		chirpsJson = append(chirpsJson, jsonChirp{
+			Id:        chirp.ID,
+			CreatedAt: chirp.CreatedAt,
+			UpdatedAt: chirp.UpdatedAt,
+			Body:      chirp.Body,
+			UserId:    chirp.UserID,
+			Status:    chirp.Status,
+		})
+	}
+	sessionsJson := []jsonExportSession{}
+	for _, refreshToken := range refreshTokens {
+		session := jsonExportSession{
+			CreatedAt: refreshToken.CreatedAt,
+			UpdatedAt: refreshToken.UpdatedAt,
+			ExpiresAt: refreshToken.ExpiresAt,
+			Device:    refreshToken.Device,
+			UserAgent: refreshToken.UserAgent,
+			IpAddress: refreshToken.IpAddress,
+		}
+		if refreshToken.RevokedAt.Valid {
+			session.RevokedAt = &refreshToken.RevokedAt.Time
+		}
+		sessionsJson = append(sessionsJson, session)
+	}
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for _, file := range []struct {
+		name    string
+		content any
+	}{
+		{exportFileProfile, profile},
+		{exportFileChirps, chirpsJson},
+		{exportFileSessions, sessionsJson},
+	} {
+		var fileWriter io.Writer
+		if fileWriter, err = writer.Create(file.name); err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(fileWriter)
+		encoder.SetIndent(empty, exportIndent)
+		if err = encoder.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err = writeExportChirpsCsv(writer, chirps); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+func writeExportChirpsCsv(writer *zip.Writer, chirps []database.Chirp) error {
+	file, err := writer.Create(exportFileChirpsCsv)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(file)
+	if err = csvWriter.Write(exportChirpsCsvHeader); err != nil {
+		return err
+	}
+	for _, chirp := range chirps {
+		if err = csvWriter.Write([]string{
+			chirp.ID.String(),
+			chirp.CreatedAt.Format(time.RFC3339),
+			chirp.UpdatedAt.Format(time.RFC3339),
+			chirp.Status,
+			chirp.Body,
+		}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func (c *ApiConfig) exportDownloadUrl(export database.DataExport) string {
+	expires := strconv.FormatInt(time.Now().Add(exportUrlExpiry).Unix(), 10)
+	query := url.Values{}
+	query.Set(queryExpires, expires)
+	query.Set(querySignature, c.exportSignature(export.ID, expires))
+	return fmt.Sprintf("%s/api/exports/%s/download?%s", c.BaseUrl, export.ID, query.Encode())
+}
+
+func (c *ApiConfig) validateExportDownload(exportId uuid.UUID, expires string,
+	signature string) error {
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return err
+	}
+	if time.Now().Unix() > expiresUnix {
+		return errors.New(errorExportLinkExpired)
+	}
+	return auth.ValidateSignature(exportSignatureMessage(exportId, expires), signature, c.Secret)
+}
+
+func (c *ApiConfig) exportSignature(exportId uuid.UUID, expires string) string {
+	return auth.MakeSignature(exportSignatureMessage(exportId, expires), c.Secret)
+}
+
+func exportSignatureMessage(exportId uuid.UUID, expires string) string {
+	return exportId.String() + "." + expires
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+type exportDownloadInput struct {
+	exportId  uuid.UUID
+	expires   string
+	signature string
+	secret    string
+}
+
+func TestValidateExportDownload(t *testing.T) {
+	config := ApiConfig{Secret: "secret", BaseUrl: "http://localhost:8080"}
+	export := database.DataExport{ID: uuid.New()}
+	downloadUrl, err := url.Parse(config.exportDownloadUrl(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expires, signature := downloadUrl.Query().Get(queryExpires), downloadUrl.Query().Get(querySignature)
+	expired := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	testsOk := []exportDownloadInput{
+		{export.ID, expires, signature, config.Secret},
+	}
+	testsErr := []exportDownloadInput{
+		{uuid.New(), expires, signature, config.Secret},
+		{export.ID, expires + "0", signature, config.Secret},
+		{export.ID, expires, signature, "error"},
+		{export.ID, expired, config.exportSignature(export.ID, expired), config.Secret},
+		{export.ID, empty, signature, config.Secret},
+	}
+	for _, input := range testsOk {
+		inputConfig := ApiConfig{Secret: input.secret}
+		if err := inputConfig.validateExportDownload(input.exportId, input.expires, input.signature); err != nil {
+			t.Errorf(
+				"validateExportDownload(%s, \"%s\", \"%s\") = (%v), want (nil)",
+				input.exportId, input.expires, input.signature, err,
+			)
+		}
+	}
+	for _, input := range testsErr {
+		inputConfig := ApiConfig{Secret: input.secret}
+		if err := inputConfig.validateExportDownload(input.exportId, input.expires, input.signature); err == nil {
+			t.Errorf(
+				"validateExportDownload(%s, \"%s\", \"%s\") = (nil), want (error)",
+				input.exportId, input.expires, input.signature,
+			)
+		}
+	}
+}
+
+func TestRequestDataExport(t *testing.T) {
+	userId, now := uuid.New(), time.Now()
+	export := testResult{
+		columns: []string{"id", "created_at", "updated_at", "user_id", "status", "archive", "expires_at"},
+		rows:    [][]driver.Value{{uuid.NewString(), now, now, userId.String(), "pending", nil, now}},
+	}
+	tests := map[int64]error{
+		0:               nil,
+		exportLimit - 1: nil,
+		exportLimit:     errExportLimit,
+	}
+	for input, want := range tests {
+		db, _ := newTestDb(map[string]testResult{
+			"CountDataExportsFromUser": {columns: []string{"count"}, rows: [][]driver.Value{{input}}},
+			"CreateDataExport":         export,
+		})
+		config := ApiConfig{DBQueries: database.New(db), dataExports: make(chan database.DataExport, 1)}
+		if _, err := config.requestDataExport(context.Background(), userId); !errors.Is(err, want) {
+			t.Errorf(
+				"requestDataExport() with %d recent = (%v), want (%v)",
+				input, err, want,
+			)
+		}
+	}
+
+	db, testDb := newTestDb(map[string]testResult{
+		"CountDataExportsFromUser": {columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}},
+		"CreateDataExport":         export,
+	})
+	config := ApiConfig{DBQueries: database.New(db), dataExports: make(chan database.DataExport)}
+	if _, err := config.requestDataExport(context.Background(), userId); !errors.Is(err, errExportQueueFull) ||
+		!slices.Contains(testDb.ran(), "UpdateDataExportFailed") {
+		t.Errorf(
+			"requestDataExport() with a full queue = (%v, %v), want (%v, failed)",
+			err, testDb.ran(), errExportQueueFull,
+		)
+	}
+}
@@ -2,9 +2,13 @@ package web
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 	"slices"
 	"time"
 
@@ -25,7 +29,7 @@ func HandlerApp(config *ApiConfig) http.Handler {
 }
 
 func HandlerGetAdminMetrics(config *ApiConfig) http.HandlerFunc {
-	return func(w http.ResponseWriter, _ *http.Request) {
+	return config.middleRole(roleAdmin, func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set(headerContentType, contentTypeHtml)
 		if _, err := w.Write([]byte(fmt.Sprintf(empty+
 			"<html>\n"+
@@ -38,186 +42,1882 @@ func HandlerGetAdminMetrics(config *ApiConfig) http.HandlerFunc {
 		))); err != nil {
 			log.Fatal(err)
 		}
-	}
+	})
 }
 
 func HandlerPostAdminReset(config *ApiConfig) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+	return config.middleRole(roleAdmin, func(w http.ResponseWriter, r *http.Request) {
 		if config.Platform != platformDev {
 			respPlainForbidden(w, r)
 			return
 		}
-		if config.DBQueries.DeleteUsers(r.Context()) != nil {
-			respPlainBadRequest(w, r, errorSomethingWentWrong)
+		if config.DBQueries.DeleteUsers(r.Context()) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		config.middleMetricsReset(respPlainOk)
+	})
+}
+
+func HandlerGetAdminProfanities(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var profanities []database.Profanity
+		if profanities, err = config.DBQueries.GetProfanities(r.Context()); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonProfanities(w, r, profanities)
+	})
+}
+
+func HandlerPostAdminProfanities(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var profanity database.Profanity
+		request := struct {
+			Word string `json:"word"`
+			Mode string `json:"mode"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if len(request.Mode) == 0 {
+			request.Mode = profanityModeWord
+		}
+		request.Word = normaliseProfanity(request.Word)
+		if len(request.Word) == 0 ||
+			(request.Mode != profanityModeWord && request.Mode != profanityModeSubstring) {
+			respJsonBadRequest(w, r, errorInvalidProfanity)
+			return
+		}
+		if profanity, err = config.DBQueries.CreateProfanity(
+			r.Context(),
+			database.CreateProfanityParams{
+				Word: request.Word,
+				Mode: request.Mode,
+			},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if config.LoadProfanities(r.Context()) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonProfanityCreated(w, r, profanity)
+	})
+}
+
+func HandlerDeleteAdminProfanitiesWord(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		if config.DBQueries.DeleteProfanity(
+			r.Context(),
+			normaliseProfanity(r.PathValue("word")),
+		) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if config.LoadProfanities(r.Context()) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func HandlerPutAdminUsersIdRole(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var userId uuid.UUID
+		var user database.User
+		if userId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		request := struct {
+			Role string `json:"role"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if !IsRole(request.Role) {
+			respJsonBadRequest(w, r, errorInvalidRole)
+			return
+		}
+		if user, err = config.DBQueries.UpdateUserRole(
+			r.Context(),
+			database.UpdateUserRoleParams{
+				ID:   userId,
+				Role: request.Role,
+			},
+		); err != nil || user.ID == uuid.Nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		respJsonUser(w, r, user, empty, empty)
+	})
+}
+
+func HandlerDeleteAdminUsersIdRole(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var userId uuid.UUID
+		var user database.User
+		if userId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.UpdateUserRole(
+			r.Context(),
+			database.UpdateUserRoleParams{
+				ID:   userId,
+				Role: roleUser,
+			},
+		); err != nil || user.ID == uuid.Nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		respJsonUser(w, r, user, empty, empty)
+	})
+}
+
+func HandlerPutAdminUsersIdSuspension(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var userId uuid.UUID
+		if userId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if config.suspend(r.Context(), userId) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func HandlerDeleteAdminUsersIdSuspension(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var userId uuid.UUID
+		if userId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if config.DBQueries.UpdateUserUnsuspended(r.Context(), userId) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func HandlerDeleteAdminUsersIdLockout(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleAdmin, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var userId uuid.UUID
+		var user database.User
+		if userId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(r.Context(), userId); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if config.unlockUser(r.Context(), user) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func HandlerPostApiUsers(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var hash string
+		var user database.User
+		request := struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if hash, err = auth.HashPassword(request.Password); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.CreateUser(
+			r.Context(),
+			database.CreateUserParams{
+				Email:          request.Email,
+				HashedPassword: hash,
+			},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		config.sendEmailVerificationAsync(user)
+		respJsonUserCreated(w, r, user)
+	}
+}
+
+func HandlerGetApiUsersUser(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var user database.User
+		if user, err = config.getUserFromIdOrUsername(
+			r.Context(),
+			r.PathValue("user"),
+		); err != nil || user.ID == uuid.Nil || !isUserActive(user) {
+			respPlainNotFound(w, r)
+			return
+		}
+		respJsonProfile(w, r, user)
+	}
+}
+
+func HandlerPatchApiUsersMe(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var patch userPatch
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeProfileWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headerContentType)); mediaType {
+		case contentTypeMergePatch, mediaTypeJson:
+		default:
+			respJsonBadRequest(w, r, errorInvalidPatch)
+			return
+		}
+		if patch, err = decodeUserPatch(r.Body); err != nil {
+			respJsonBadRequest(w, r, err.Error())
+			return
+		}
+		handleUserPatch(w, r, config, token, user, patch)
+	}
+}
+
+// HandlerPutApiUsers is the deprecated way of changing credentials, kept for
+// older clients. It replaces email and password at once and goes through the
+// same checks as PATCH /api/users/me.
+func HandlerPutApiUsers(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		w.Header().Set(headerDeprecation, deprecationTrue)
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		request := struct {
+			Email           string  `json:"email"`
+			Password        string  `json:"password"`
+			CurrentPassword *string `json:"current_password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil || len(request.Email) == 0 ||
+			len(request.Password) == 0 {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		handleUserPatch(w, r, config, token, user, userPatch{
+			Email:           &request.Email,
+			Password:        &request.Password,
+			CurrentPassword: request.CurrentPassword,
+		})
+	}
+}
+
+// handleUserPatch applies a decoded patch for the user the token belongs to.
+// Changing the email or password takes the current password and a session,
+// and a new password revokes every other session.
+func handleUserPatch(w http.ResponseWriter, r *http.Request, config *ApiConfig, token string,
+	user database.User, patch userPatch) {
+	var err error
+	var refreshToken string
+	var profile database.UpdateUserProfileParams
+	var client *sessionClient
+	var session database.RefreshToken
+	emailChanged := patch.Email != nil && *patch.Email != user.Email
+	if emailChanged || patch.Password != nil {
+		// credentials stay out of reach of personal access tokens
+		if auth.IsPersonalAccessToken(token) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		}
+		if patch.CurrentPassword == nil ||
+			auth.ValidateHash(*patch.CurrentPassword, user.HashedPassword) != nil {
+			respJsonUnauthorized(w, r, errorInvalidPassword)
+			return
+		}
+	}
+	if profile, err = config.applyProfileUpdate(
+		r.Context(),
+		user,
+		patch.profileUpdate,
+	); err != nil {
+		var invalid profileError
+		if !errors.As(err, &invalid) {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		} else if invalid == errorUsernameTaken {
+			respJsonConflict(w, r, invalid.Error())
+		} else {
+			respJsonBadRequest(w, r, invalid.Error())
+		}
+		return
+	}
+	credentials := database.UpdateUserCredentialsParams{
+		ID:             user.ID,
+		Email:          user.Email,
+		HashedPassword: user.HashedPassword,
+	}
+	if emailChanged {
+		credentials.Email = *patch.Email
+	}
+	if patch.Password != nil {
+		if credentials.HashedPassword, err = auth.HashPassword(*patch.Password); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		// every other session is revoked, so hand this one fresh tokens
+		current := newSessionClient(r)
+		client = &current
+	}
+	if user, refreshToken, session, err = config.updateUser(
+		r.Context(),
+		credentials,
+		profile,
+		client,
+	); err != nil {
+		respJsonBadRequest(w, r, errorSomethingWentWrong)
+		return
+	}
+	if emailChanged {
+		config.sendEmailVerificationAsync(user)
+	}
+	token = empty
+	if len(refreshToken) > 0 {
+		if token, err = config.makeAccessToken(user, session); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if isCookieSession(r) {
+			if setSessionCookies(w, token, refreshToken) != nil {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+				return
+			}
+			token, refreshToken = empty, empty
+		}
+	}
+	respJsonUser(w, r, user, token, refreshToken)
+}
+
+func HandlerGetApiUsersIdAvatar(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var userId uuid.UUID
+		var user database.User
+		var avatar database.Avatar
+		if userId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil || !isUserActive(user) {
+			respPlainNotFound(w, r)
+			return
+		}
+		if avatar, err = config.DBQueries.GetAvatar(r.Context(), userId); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		respImageAvatar(w, r, avatar)
+	}
+}
+
+func HandlerPutApiUsersMeAvatar(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token, contentType string
+		var userId uuid.UUID
+		var user database.User
+		var body []byte
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeProfileWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, avatarMaxBytes)); err != nil {
+			respJsonBadRequest(w, r, errorInvalidAvatar)
+			return
+		}
+		if contentType, err = checkAvatar(body); err != nil {
+			respJsonBadRequest(w, r, errorInvalidAvatar)
+			return
+		}
+		if err = config.setUserAvatar(r.Context(), userId, contentType, body); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(r.Context(), userId); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonUser(w, r, user, empty, empty)
+	}
+}
+
+func HandlerDeleteApiUsersMeAvatar(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeProfileWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if config.deleteUserAvatar(r.Context(), userId) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiUsersMeDeactivate(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respPlainUnauthorized(w, r)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respPlainForbidden(w, r)
+			return
+		} else if err != nil {
+			respPlainUnauthorized(w, r)
+			return
+		}
+		if config.deactivateUser(r.Context(), userId) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerDeleteApiUsersMe(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var receipt database.ErasureReceipt
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		request := struct {
+			Password string `json:"password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || auth.ValidateHash(request.Password, user.HashedPassword) != nil {
+			respJsonUnauthorized(w, r, errorInvalidPassword)
+			return
+		}
+		if receipt, err = config.eraseUser(r.Context(), user); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonErasureReceipt(w, r, receipt)
+	}
+}
+
+func HandlerPostApiUsersMeExport(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var export database.DataExport
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if export, err = config.requestDataExport(
+			r.Context(),
+			userId,
+		); errors.Is(err, errExportLimit) {
+			respJsonTooManyRequests(w, r, errorTooManyExports, exportWindow)
+			return
+		} else if errors.Is(err, errExportQueueFull) {
+			respJsonTooManyRequests(w, r, errorExportQueueFull, exportRetryAfter)
+			return
+		} else if err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonDataExportAccepted(w, r, export)
+	}
+}
+
+func HandlerGetApiUsersMeExportId(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId, exportId uuid.UUID
+		var export database.DataExport
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if exportId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if export, err = config.DBQueries.GetDataExport(
+			r.Context(),
+			exportId,
+		); err != nil || export.UserID != userId {
+			respPlainNotFound(w, r)
+			return
+		}
+		downloadUrl := empty
+		if export.Status == exportStatusReady {
+			downloadUrl = config.exportDownloadUrl(export)
+		}
+		respJsonDataExport(w, r, export, downloadUrl)
+	}
+}
+
+func HandlerGetApiExportsIdDownload(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var exportId uuid.UUID
+		var export database.DataExport
+		if exportId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if config.validateExportDownload(
+			exportId,
+			r.URL.Query().Get(queryExpires),
+			r.URL.Query().Get(querySignature),
+		) != nil {
+			respPlainForbidden(w, r)
+			return
+		}
+		if export, err = config.DBQueries.GetDataExport(
+			r.Context(),
+			exportId,
+		); err != nil || export.Status != exportStatusReady {
+			respPlainNotFound(w, r)
+			return
+		}
+		respZipDataExport(w, r, export)
+	}
+}
+
+func HandlerPostApiUsersMeImport(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var result ImportResult
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeChirpsWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if !isUserActive(user) {
+			respJsonForbidden(w, r, errorAccountInactive)
+			return
+		}
+		if !config.canChirp(user) {
+			respJsonForbidden(w, r, errorEmailUnverified)
+			return
+		}
+		var format string
+		switch mediaType, _, _ := mime.ParseMediaType(r.Header.Get(headerContentType)); mediaType {
+		case contentTypeCsv:
+			format = importFormatCsv
+		case contentTypeNdjson:
+			format = importFormatNdjson
+		default:
+			respJsonBadRequest(w, r, errorInvalidImportFormat)
+			return
+		}
+		if result, err = config.ImportChirps(
+			r.Context(),
+			user,
+			format,
+			http.MaxBytesReader(w, r.Body, importMaxBytes),
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonImportResult(w, r, result)
+	}
+}
+
+func HandlerGetApiVerify(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if config.verifyEmail(r.Context(), r.URL.Query().Get(queryToken)) != nil {
+			respPlainBadRequest(w, r, errorInvalidToken)
+			return
+		}
+		respPlainOk(w, r)
+	}
+}
+
+func HandlerPostApiVerifyResend(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var recent int64
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user.EmailVerifiedAt.Valid {
+			respJsonBadRequest(w, r, errorEmailAlreadyVerified)
+			return
+		}
+		if recent, err = config.DBQueries.CountEmailVerificationsFromUser(
+			r.Context(),
+			database.CountEmailVerificationsFromUserParams{
+				UserID:    user.ID,
+				CreatedAt: time.Now().Add(-verificationWindow),
+			},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if recent >= verificationLimit {
+			respJsonTooManyRequests(w, r, errorTooManyRequests, verificationWindow)
+			return
+		}
+		if config.sendEmailVerification(r.Context(), user) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func HandlerPostApiPasswordForgot(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Email string `json:"email"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil || len(request.Email) == 0 {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		config.sendPasswordResetAsync(request.Email)
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func HandlerPostApiPasswordReset(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var hash string
+		request := struct {
+			Token    string `json:"token"`
+			Password string `json:"password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil || len(request.Password) == 0 {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if hash, err = auth.HashPassword(request.Password); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if config.resetPassword(r.Context(), request.Token, hash) != nil {
+			respJsonBadRequest(w, r, errorInvalidToken)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiUsersMeMfaTotp(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var enrolment totpEnrolment
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user.TotpEnabledAt.Valid {
+			respJsonConflict(w, r, errorMfaAlreadyEnabled)
+			return
+		}
+		if enrolment, err = config.startTotpEnrolment(r.Context(), user); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonTotpEnrolment(w, r, enrolment)
+	}
+}
+
+func HandlerPostApiUsersMeMfaTotpConfirm(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var recoveryCodes []string
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user.TotpEnabledAt.Valid {
+			respJsonConflict(w, r, errorMfaAlreadyEnabled)
+			return
+		}
+		request := struct {
+			Code string `json:"code"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if recoveryCodes, err = config.confirmTotp(r.Context(), user, request.Code); err != nil {
+			respJsonBadRequest(w, r, errorInvalidMfaCode)
+			return
+		}
+		respJsonRecoveryCodes(w, r, recoveryCodes)
+	}
+}
+
+func HandlerDeleteApiUsersMeMfaTotp(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		request := struct {
+			Password string `json:"password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || auth.ValidateHash(request.Password, user.HashedPassword) != nil {
+			respJsonUnauthorized(w, r, errorInvalidPassword)
+			return
+		}
+		if config.disableTotp(r.Context(), user.ID) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiUsersMeMfaRecoveryCodes(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var user database.User
+		var recoveryCodes []string
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		request := struct {
+			Password string `json:"password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || auth.ValidateHash(request.Password, user.HashedPassword) != nil {
+			respJsonUnauthorized(w, r, errorInvalidPassword)
+			return
+		}
+		if !user.TotpEnabledAt.Valid {
+			respJsonBadRequest(w, r, errorMfaNotEnabled)
+			return
+		}
+		if recoveryCodes, err = config.regenerateRecoveryCodes(r.Context(), user.ID); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonRecoveryCodes(w, r, recoveryCodes)
+	}
+}
+
+func HandlerGetWellKnownJwks(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respJsonJwks(w, r, config.Keys.Jwks())
+	}
+}
+
+func HandlerPostApiLogin(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var retryAfter time.Duration
+		var user database.User
+		request := struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user, retryAfter, err = config.checkPassword(
+			r.Context(),
+			request.Email,
+			request.Password,
+			clientIp(r),
+		); errors.Is(err, errLoginThrottled) {
+			respJsonTooManyRequests(w, r, errorTooManyLoginAttempts, retryAfter)
+			return
+		} else if errors.Is(err, errInvalidCredentials) {
+			respJsonUnauthorized(w, r, errorInvalidEmailPassword)
+			return
+		} else if err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		handleLogIn(w, r, config, user, errorInvalidEmailPassword)
+	}
+}
+
+// handleLogIn finishes every way of logging in once the user has proven who
+// they are: blocked accounts are turned away, MFA is asked for when enabled,
+// and only then are tokens issued.
+func handleLogIn(w http.ResponseWriter, r *http.Request, config *ApiConfig, user database.User,
+	unauthorized string) {
+	var err error
+	var token, refreshToken string
+	if user.SuspendedAt.Valid {
+		respJsonForbidden(w, r, errorAccountSuspended)
+		return
+	}
+	if user.DeactivatedAt.Valid && time.Since(user.DeactivatedAt.Time) > config.DeactivationPeriod {
+		respJsonUnauthorized(w, r, unauthorized)
+		return
+	}
+	if user.TotpEnabledAt.Valid {
+		var mfaToken string
+		var challenge database.MfaChallenge
+		if mfaToken, challenge, err = config.createMfaChallenge(r.Context(), user.ID); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonMfaChallenge(w, r, mfaToken, challenge)
+		return
+	}
+	if user, token, refreshToken, err = config.logIn(r.Context(), user, newSessionClient(r)); err != nil {
+		respJsonBadRequest(w, r, errorSomethingWentWrong)
+		return
+	}
+	if config.CookieSessions {
+		if setSessionCookies(w, token, refreshToken) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		// scripts never get to see tokens kept in cookies
+		token, refreshToken = empty, empty
+	}
+	respJsonUser(w, r, user, token, refreshToken)
+}
+
+func HandlerPostApiLoginMagic(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Email string `json:"email"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil || len(request.Email) == 0 {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		config.sendMagicLinkAsync(request.Email, requestFingerprint(r))
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func HandlerGetApiLoginMagicCallback(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var user database.User
+		if user, err = config.useMagicLink(
+			r.Context(),
+			r.URL.Query().Get(queryToken),
+			requestFingerprint(r),
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		handleLogIn(w, r, config, user, errorInvalidToken)
+	}
+}
+
+func HandlerGetApiLoginOidc(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if config.Oidc == nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		authCodeUrl, err := config.startOidcLogin(r.Context(), requestFingerprint(r))
+		if err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.Header().Set(headerCacheControl, cacheControlNoStore)
+		http.Redirect(w, r, authCodeUrl, http.StatusFound)
+	}
+}
+
+func HandlerGetApiLoginOidcCallback(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var user database.User
+		if config.Oidc == nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if user, err = config.finishOidcLogin(
+			r.Context(),
+			r.URL.Query().Get(oauthParamState),
+			r.URL.Query().Get(oauthParamCode),
+			requestFingerprint(r),
+		); errors.Is(err, errOidcNoAccount) {
+			respJsonUnauthorized(w, r, errorOidcNoAccount)
+			return
+		} else if err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		handleLogIn(w, r, config, user, errorInvalidToken)
+	}
+}
+
+func HandlerPostApiLoginMfa(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token, refreshToken string
+		var attempts int32
+		var retryAfter time.Duration
+		var user database.User
+		var challenge database.MfaChallenge
+		request := struct {
+			MfaToken     string `json:"mfa_token"`
+			Code         string `json:"code"`
+			RecoveryCode string `json:"recovery_code"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if challenge, err = config.DBQueries.GetMfaChallengeFromHash(
+			r.Context(),
+			auth.HashToken(request.MfaToken),
+		); err != nil {
+			respJsonUnauthorized(w, r, errorInvalidMfaToken)
+			return
+		}
+		if attempts, err = config.DBQueries.UpdateMfaChallengeAttempts(
+			r.Context(),
+			challenge.ID,
+		); err != nil || attempts > mfaMaxAttempts {
+			if config.DBQueries.DeleteMfaChallenge(r.Context(), challenge.ID) != nil {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+				return
+			}
+			respJsonUnauthorized(w, r, errorInvalidMfaToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			challenge.UserID,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidMfaToken)
+			return
+		}
+		if retryAfter, err = config.checkMfa(
+			r.Context(),
+			user,
+			request.Code,
+			request.RecoveryCode,
+		); errors.Is(err, errLoginThrottled) {
+			respJsonTooManyRequests(w, r, errorTooManyLoginAttempts, retryAfter)
+			return
+		} else if errors.Is(err, errInvalidMfaCode) {
+			respJsonUnauthorized(w, r, errorInvalidMfaCode)
+			return
+		} else if err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if err = config.DBQueries.DeleteMfaChallenge(r.Context(), challenge.ID); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if user.SuspendedAt.Valid {
+			respJsonForbidden(w, r, errorAccountSuspended)
+			return
+		}
+		if user, token, refreshToken, err = config.logIn(r.Context(), user, newSessionClient(r)); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if config.CookieSessions {
+			if setSessionCookies(w, token, refreshToken) != nil {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+				return
+			}
+			token, refreshToken = empty, empty
+		}
+		respJsonUser(w, r, user, token, refreshToken)
+	}
+}
+
+func HandlerPostApiRefresh(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token, refreshToken string
+		var user database.User
+		var session database.RefreshToken
+		if refreshToken, err = getRefreshToken(r); err != nil {
+			respJsonUnauthorized(w, r, errorMissingRefreshToken)
+			return
+		}
+		if session, refreshToken, err = config.rotateRefreshToken(
+			r.Context(),
+			refreshToken,
+			newSessionClient(r),
+			uuid.NullUUID{},
+		); errors.Is(err, errRefreshTokenReused) {
+			respJsonUnauthorized(w, r, errorRefreshTokenReused)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidRefreshToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			session.UserID.UUID,
+		); err != nil || user.ID == uuid.Nil {
+			respJsonUnauthorized(w, r, errorInvalidRefreshToken)
+			return
+		}
+		if user.SuspendedAt.Valid {
+			if config.DBQueries.DeleteRefreshTokensFromUser(
+				r.Context(),
+				uuid.NullUUID{UUID: user.ID, Valid: true},
+			) != nil {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+				return
+			}
+			respJsonForbidden(w, r, errorAccountSuspended)
+			return
+		}
+		if user.DeactivatedAt.Valid {
+			respJsonUnauthorized(w, r, errorInvalidRefreshToken)
+			return
+		}
+		if token, err = config.makeAccessToken(user, session); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if isCookieSession(r) {
+			if setSessionCookies(w, token, refreshToken) != nil {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+				return
+			}
+			token, refreshToken = empty, empty
+		}
+		respJsonToken(w, r, token, refreshToken)
+	}
+}
+
+func HandlerGetApiSessions(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var claims auth.Claims
+		var sessions []database.RefreshToken
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if claims, err = config.Keys.ValidateJWTClaims(token); err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if len(claims.ClientId) > 0 {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		}
+		if sessions, err = config.DBQueries.GetSessionsFromUser(
+			r.Context(),
+			uuid.NullUUID{UUID: uuid.MustParse(claims.Subject), Valid: true},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonSessions(w, r, sessions, claims.SessionId)
+	}
+}
+
+func HandlerDeleteApiSessionsId(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId, sessionId uuid.UUID
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if sessionId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if err = config.deleteSession(r.Context(), userId, sessionId); errors.Is(err, errSessionNotFound) {
+			respPlainNotFound(w, r)
+			return
+		} else if err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiLogoutAll(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if config.logOutAll(r.Context(), userId) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if isCookieSession(r) {
+			clearSessionCookies(w)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiTokens(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token, accessTokenSecret string
+		var userId uuid.UUID
+		var accessToken database.PersonalAccessToken
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		request := struct {
+			Name          string   `json:"name"`
+			Scopes        []string `json:"scopes"`
+			ExpiresInDays int      `json:"expires_in_days"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if accessToken, accessTokenSecret, err = config.createPersonalAccessToken(
+			r.Context(),
+			userId,
+			request.Name,
+			request.Scopes,
+			request.ExpiresInDays,
+		); err != nil {
+			var invalid tokenError
+			if errors.As(err, &invalid) {
+				respJsonBadRequest(w, r, invalid.Error())
+			} else {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+			}
+			return
+		}
+		respJsonPersonalAccessTokenCreated(w, r, accessToken, accessTokenSecret)
+	}
+}
+
+func HandlerGetApiTokens(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var accessTokens []database.PersonalAccessToken
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if accessTokens, err = config.DBQueries.GetPersonalAccessTokensFromUser(
+			r.Context(),
+			userId,
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonPersonalAccessTokens(w, r, accessTokens)
+	}
+}
+
+func HandlerDeleteApiTokensId(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId, tokenId uuid.UUID
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if tokenId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if err = config.deletePersonalAccessToken(
+			r.Context(),
+			userId,
+			tokenId,
+		); errors.Is(err, errPersonalAccessTokenNotFound) {
+			respPlainNotFound(w, r)
+			return
+		} else if err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiRevoke(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var refreshToken string
+		if refreshToken, err = getRefreshToken(r); err != nil {
+			respPlainBadRequest(w, r, errorMissingRefreshToken)
+			return
+		}
+		if config.DBQueries.DeleteRefreshToken(
+			r.Context(),
+			auth.HashToken(refreshToken),
+		) != nil {
+			respPlainBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if isCookieSession(r) {
+			clearSessionCookies(w)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func HandlerPostApiOauthClients(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token, secret string
+		var userId uuid.UUID
+		var client database.OauthClient
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		request := struct {
+			Name         string   `json:"name"`
+			RedirectUris []string `json:"redirect_uris"`
+			Confidential bool     `json:"confidential"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if client, secret, err = config.createOauthClient(
+			r.Context(),
+			userId,
+			request.Name,
+			request.RedirectUris,
+			request.Confidential,
+		); err != nil {
+			var invalid clientError
+			if errors.As(err, &invalid) {
+				respJsonBadRequest(w, r, invalid.Error())
+			} else {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+			}
+			return
+		}
+		respJsonOauthClientCreated(w, r, client, secret)
+	}
+}
+
+func HandlerGetApiOauthClients(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId uuid.UUID
+		var clients []database.OauthClient
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
 			return
 		}
-		config.middleMetricsReset(respPlainOk)
+		if clients, err = config.DBQueries.GetOauthClientsFromUser(r.Context(), userId); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonOauthClients(w, r, clients)
 	}
 }
 
-func HandlerPostApiUsers(config *ApiConfig) http.HandlerFunc {
+func HandlerDeleteApiOauthClientsId(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
-		var hash string
-		var user database.User
-		request := struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-		}{}
-		if json.NewDecoder(r.Body).Decode(&request) != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		var token string
+		var userId, clientId uuid.UUID
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
 			return
 		}
-		if hash, err = auth.HashPassword(request.Password); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
 			return
 		}
-		if user, err = config.DBQueries.CreateUser(
+		if clientId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		if err = config.deleteOauthClient(
 			r.Context(),
-			database.CreateUserParams{
-				Email:          request.Email,
-				HashedPassword: hash,
-			},
-		); err != nil {
+			userId,
+			clientId,
+		); errors.Is(err, errOauthClientNotFound) {
+			respPlainNotFound(w, r)
+			return
+		} else if err != nil {
 			respJsonBadRequest(w, r, errorSomethingWentWrong)
 			return
 		}
-		respJsonUserCreated(w, r, user)
+		w.WriteHeader(http.StatusNoContent)
 	}
 }
 
-func HandlerPutApiUsers(config *ApiConfig) http.HandlerFunc {
+func HandlerGetOauthAuthorize(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authorization, trusted, err := config.parseOauthAuthorization(r.Context(), r.URL.Query())
+		if !trusted {
+			respHtmlOauthAuthorize(w, r, http.StatusBadRequest, oauthAuthorizePage{Error: err.Error()})
+			return
+		}
+		var oauthErr oauthError
+		if errors.As(err, &oauthErr) {
+			respRedirectOauth(w, r, authorization, url.Values{
+				oauthParamError:            {oauthErr.Code},
+				oauthParamErrorDescription: {oauthErr.Description},
+			})
+			return
+		}
+		respHtmlOauthAuthorize(w, r, http.StatusOK, newOauthAuthorizePage(authorization, empty, empty))
+	}
+}
+
+func HandlerPostOauthAuthorize(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
-		var token, hash string
-		var userId uuid.UUID
+		var code string
 		var user database.User
-		if token, err = auth.GetBearerToken(r.Header); err != nil {
-			respJsonUnauthorized(w, r, errorMissingToken)
+		var authorization oauthAuthorization
+		var trusted bool
+		if r.ParseForm() != nil {
+			respHtmlOauthAuthorize(w, r, http.StatusBadRequest, oauthAuthorizePage{Error: errorSomethingWentWrong})
 			return
 		}
-		if userId, err = auth.ValidateJWT(token, config.Secret); err != nil {
-			respJsonUnauthorized(w, r, errorInvalidToken)
+		if authorization, trusted, err = config.parseOauthAuthorization(r.Context(), r.PostForm); !trusted {
+			respHtmlOauthAuthorize(w, r, http.StatusBadRequest, oauthAuthorizePage{Error: err.Error()})
 			return
 		}
-		request := struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-		}{}
-		if json.NewDecoder(r.Body).Decode(&request) != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		var oauthErr oauthError
+		if errors.As(err, &oauthErr) {
+			respRedirectOauth(w, r, authorization, url.Values{
+				oauthParamError:            {oauthErr.Code},
+				oauthParamErrorDescription: {oauthErr.Description},
+			})
 			return
 		}
-		if hash, err = auth.HashPassword(request.Password); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		if r.PostForm.Get(oauthParamDecision) != oauthDecisionApprove {
+			respRedirectOauth(w, r, authorization, url.Values{
+				oauthParamError:            {oauthErrorAccessDenied},
+				oauthParamErrorDescription: {errorOauthAccessDenied},
+			})
 			return
 		}
-		if user, err = config.DBQueries.UpdateUserCredentials(
+		email := r.PostForm.Get(oauthParamEmail)
+		if user, _, err = config.checkPassword(
 			r.Context(),
-			database.UpdateUserCredentialsParams{
-				ID:             userId,
-				Email:          request.Email,
-				HashedPassword: hash,
-			},
-		); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			email,
+			r.PostForm.Get(oauthParamPassword),
+			clientIp(r),
+		); errors.Is(err, errLoginThrottled) {
+			respHtmlOauthAuthorize(
+				w,
+				r,
+				http.StatusTooManyRequests,
+				newOauthAuthorizePage(authorization, email, errorTooManyLoginAttempts),
+			)
+			return
+		} else if err != nil || !isUserActive(user) {
+			respHtmlOauthAuthorize(
+				w,
+				r,
+				http.StatusUnauthorized,
+				newOauthAuthorizePage(authorization, email, errorInvalidEmailPassword),
+			)
 			return
 		}
-		respJsonUser(w, r, user, empty, empty)
+		if user.TotpEnabledAt.Valid {
+			if _, err = config.checkMfa(
+				r.Context(),
+				user,
+				r.PostForm.Get(oauthParamMfaCode),
+				empty,
+			); errors.Is(err, errLoginThrottled) {
+				respHtmlOauthAuthorize(
+					w,
+					r,
+					http.StatusTooManyRequests,
+					newOauthAuthorizePage(authorization, email, errorTooManyLoginAttempts),
+				)
+				return
+			} else if err != nil {
+				respHtmlOauthAuthorize(
+					w,
+					r,
+					http.StatusUnauthorized,
+					newOauthAuthorizePage(authorization, email, errorInvalidMfaCode),
+				)
+				return
+			}
+		}
+		if code, err = config.authorizeOauthClient(r.Context(), authorization, user.ID); err != nil {
+			respHtmlOauthAuthorize(
+				w,
+				r,
+				http.StatusBadRequest,
+				newOauthAuthorizePage(authorization, email, errorSomethingWentWrong),
+			)
+			return
+		}
+		respRedirectOauth(w, r, authorization, url.Values{
+			oauthParamCode: {code},
+		})
 	}
 }
 
-func HandlerPostApiLogin(config *ApiConfig) http.HandlerFunc {
+func HandlerPostOauthToken(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
 		var token, refreshToken string
+		var oauthClient database.OauthClient
 		var user database.User
-		request := struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-		}{}
-		if json.NewDecoder(r.Body).Decode(&request) != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		var session database.RefreshToken
+		if r.ParseForm() != nil {
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthInvalidRequest)
 			return
 		}
-		if user, err = config.DBQueries.GetUser(
-			r.Context(),
-			request.Email,
-		); err != nil || auth.ValidateHash(request.Password, user.HashedPassword) != nil {
-			respJsonUnauthorized(w, r, errorInvalidEmailPassword)
+		if oauthClient, err = config.authenticateOauthClient(r.Context(), r); err != nil {
+			respJsonOauthError(w, r, http.StatusUnauthorized, errOauthInvalidClient)
 			return
 		}
-		if token, err = auth.MakeJWT(
-			user.ID,
-			config.Secret,
-			time.Hour,
-		); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		switch r.PostForm.Get(oauthParamGrantType) {
+		case oauthGrantAuthorizationCode:
+			user, session, refreshToken, err = config.exchangeAuthorizationCode(
+				r.Context(),
+				oauthClient,
+				r.PostForm.Get(oauthParamCode),
+				r.PostForm.Get(oauthParamRedirectUri),
+				r.PostForm.Get(oauthParamCodeVerifier),
+				newSessionClient(r),
+			)
+		case oauthGrantRefreshToken:
+			user, session, refreshToken, err = config.refreshOauthToken(
+				r.Context(),
+				oauthClient,
+				r.PostForm.Get(oauthParamRefreshToken),
+				newSessionClient(r),
+			)
+		default:
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthGrantType)
 			return
 		}
-		if refreshToken, err = auth.MakeRefreshToken(); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		var oauthErr oauthError
+		if errors.As(err, &oauthErr) {
+			respJsonOauthError(w, r, http.StatusBadRequest, oauthErr)
+			return
+		} else if err != nil {
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthInvalidRequest)
 			return
 		}
-		if _, err = config.DBQueries.CreateRefreshToken(
-			r.Context(),
-			database.CreateRefreshTokenParams{
-				Token:     refreshToken,
-				UserID:    uuid.NullUUID{UUID: user.ID, Valid: true},
-				ExpiresAt: time.Now().Add(time.Hour * hoursInDay * daysInMonth * 2),
-			},
-		); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		if token, err = config.makeAccessToken(user, session); err != nil {
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthInvalidRequest)
 			return
 		}
-		respJsonUser(w, r, user, token, refreshToken)
+		respJsonOauthToken(w, r, token, refreshToken, session.Scopes)
 	}
 }
 
-func HandlerPostApiRefresh(config *ApiConfig) http.HandlerFunc {
+func HandlerPostOauthRevoke(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
-		var token, refreshToken string
-		var user database.User
-		if refreshToken, err = auth.GetBearerToken(r.Header); err != nil {
-			respJsonUnauthorized(w, r, errorMissingRefreshToken)
+		var oauthClient database.OauthClient
+		if r.ParseForm() != nil {
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthInvalidRequest)
 			return
 		}
-		if user, err = config.DBQueries.GetUserFromRefreshToken(
-			r.Context(),
-			refreshToken,
-		); err != nil || user.ID == uuid.Nil {
-			respJsonUnauthorized(w, r, errorInvalidRefreshToken)
+		if oauthClient, err = config.authenticateOauthClient(r.Context(), r); err != nil {
+			respJsonOauthError(w, r, http.StatusUnauthorized, errOauthInvalidClient)
 			return
 		}
-		if token, err = auth.MakeJWT(
-			user.ID,
-			config.Secret,
-			time.Hour,
-		); err != nil {
-			respJsonBadRequest(w, r, errorSomethingWentWrong)
+		if config.revokeOauthToken(r.Context(), oauthClient, r.PostForm.Get(oauthParamToken)) != nil {
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthInvalidRequest)
 			return
 		}
-		respJsonToken(w, r, token)
+		w.WriteHeader(http.StatusOK)
 	}
 }
 
-func HandlerPostApiRevoke(config *ApiConfig) http.HandlerFunc {
+func HandlerPostOauthIntrospect(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
-		var refreshToken string
-		if refreshToken, err = auth.GetBearerToken(r.Header); err != nil {
-			respPlainBadRequest(w, r, errorMissingRefreshToken)
+		var oauthClient database.OauthClient
+		if r.ParseForm() != nil {
+			respJsonOauthError(w, r, http.StatusBadRequest, errOauthInvalidRequest)
 			return
 		}
-		if config.DBQueries.DeleteRefreshToken(
-			r.Context(),
-			refreshToken,
-		) != nil {
-			respPlainBadRequest(w, r, errorSomethingWentWrong)
+		if oauthClient, err = config.authenticateOauthClient(r.Context(), r); err != nil {
+			respJsonOauthError(w, r, http.StatusUnauthorized, errOauthInvalidClient)
 			return
 		}
-		w.WriteHeader(http.StatusNoContent)
+		respJsonOauthIntrospection(
+			w,
+			r,
+			config.introspectOauthToken(r.Context(), oauthClient, r.PostForm.Get(oauthParamToken)),
+		)
 	}
 }
 
@@ -226,6 +1926,12 @@ func HandlerGetApiChirpsId(config *ApiConfig) http.HandlerFunc {
 		var err error
 		var chirpId uuid.UUID
 		var chirp database.Chirp
+		var author database.User
+		var links map[uuid.UUID][]jsonLink
+		if !config.canReadChirps(r) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		}
 		if chirpId, err = uuid.Parse(r.PathValue("id")); err != nil {
 			respJsonBadRequest(w, r, errorSomethingWentWrong)
 			return
@@ -233,11 +1939,27 @@ func HandlerGetApiChirpsId(config *ApiConfig) http.HandlerFunc {
 		if chirp, err = config.DBQueries.GetChirp(
 			r.Context(),
 			chirpId,
-		); err != nil || chirp.ID == uuid.Nil {
+		); err != nil || chirp.ID == uuid.Nil || chirp.Status != chirpStatusPublished {
 			respPlainNotFound(w, r)
 			return
 		}
-		respJsonChirp(w, r, chirp)
+		if chirp.UserID.Valid {
+			if author, err = config.DBQueries.GetUserFromId(
+				r.Context(),
+				chirp.UserID.UUID,
+			); err != nil || !isUserActive(author) {
+				respPlainNotFound(w, r)
+				return
+			}
+		}
+		if links, err = config.getChirpLinks(
+			r.Context(),
+			[]database.Chirp{chirp},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonChirp(w, r, chirp, append([]jsonLink{}, links[chirp.ID]...))
 	}
 }
 
@@ -246,6 +1968,11 @@ func HandlerGetApiChirps(config *ApiConfig) http.HandlerFunc {
 		var err error
 		var userId uuid.UUID
 		var chirps []database.Chirp
+		var links map[uuid.UUID][]jsonLink
+		if !config.canReadChirps(r) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		}
 		userIdParam := r.URL.Query().Get("author_id")
 		if len(userIdParam) == 0 {
 			if chirps, err = config.DBQueries.GetChirps(r.Context()); err != nil {
@@ -268,24 +1995,52 @@ func HandlerGetApiChirps(config *ApiConfig) http.HandlerFunc {
 		if r.URL.Query().Get("sort") == orderDesc {
 			slices.Reverse(chirps)
 		}
-		respJsonChirps(w, r, chirps)
+		if links, err = config.getChirpLinks(r.Context(), chirps); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonChirps(w, r, chirps, links)
 	}
 }
 
-func HandlerPostApiChirps(config *ApiConfig, profanities map[string]bool) http.HandlerFunc {
+func HandlerPostApiChirps(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
 		var token string
 		var userId uuid.UUID
+		var user database.User
 		var chirp database.Chirp
+		var links []database.ChirpLink
 		if token, err = auth.GetBearerToken(r.Header); err != nil {
 			respJsonUnauthorized(w, r, errorMissingToken)
 			return
 		}
-		if userId, err = auth.ValidateJWT(token, config.Secret); err != nil {
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeChirpsWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if user, err = config.DBQueries.GetUserFromId(
+			r.Context(),
+			userId,
+		); err != nil || user.ID == uuid.Nil {
 			respJsonUnauthorized(w, r, errorInvalidToken)
 			return
 		}
+		if !isUserActive(user) {
+			respJsonForbidden(w, r, errorAccountInactive)
+			return
+		}
+		if !config.canChirp(user) {
+			respJsonForbidden(w, r, errorEmailUnverified)
+			return
+		}
 		request := struct {
 			Body string `json:"body"`
 		}{}
@@ -293,21 +2048,21 @@ func HandlerPostApiChirps(config *ApiConfig, profanities map[string]bool) http.H
 			respJsonBadRequest(w, r, errorSomethingWentWrong)
 			return
 		}
-		if len(request.Body) > 140 {
-			respJsonBadRequest(w, r, errorChirpTooLong)
+		draft := ChirpDraft{User: user, Body: request.Body, Status: chirpStatusPublished}
+		if err = config.processChirp(r.Context(), &draft); err != nil {
+			var rejections ChirpRejections
+			if errors.As(err, &rejections) {
+				respJsonChirpRejected(w, r, rejections)
+			} else {
+				respJsonBadRequest(w, r, errorSomethingWentWrong)
+			}
 			return
 		}
-		if chirp, err = config.DBQueries.CreateChirp(
-			r.Context(),
-			database.CreateChirpParams{
-				Body:   cleanProfanities(request.Body, profanities),
-				UserID: uuid.NullUUID{UUID: userId, Valid: true},
-			},
-		); err != nil {
+		if chirp, links, err = config.createChirp(r.Context(), draft); err != nil {
 			respJsonBadRequest(w, r, errorSomethingWentWrong)
 			return
 		}
-		respJsonChirpCreated(w, r, chirp)
+		respJsonChirpCreated(w, r, chirp, config.jsonLinks(links...))
 	}
 }
 
@@ -321,7 +2076,14 @@ func HandlerDeleteApiChirpsId(config *ApiConfig) http.HandlerFunc {
 			respPlainUnauthorized(w, r)
 			return
 		}
-		if userId, err = auth.ValidateJWT(token, config.Secret); err != nil {
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeChirpsWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respPlainForbidden(w, r)
+			return
+		} else if err != nil {
 			respPlainUnauthorized(w, r)
 			return
 		}
@@ -351,6 +2113,179 @@ func HandlerDeleteApiChirpsId(config *ApiConfig) http.HandlerFunc {
 	}
 }
 
+func HandlerPostApiChirpsIdReport(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var userId, chirpId uuid.UUID
+		var chirp database.Chirp
+		var report database.Report
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if userId, err = config.validateToken(
+			r.Context(),
+			token,
+			scopeChirpsWrite,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if chirpId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if chirp, err = config.DBQueries.GetChirp(
+			r.Context(),
+			chirpId,
+		); err != nil || chirp.ID == uuid.Nil || chirp.Status != chirpStatusPublished {
+			respPlainNotFound(w, r)
+			return
+		}
+		request := struct {
+			Reason  string `json:"reason"`
+			Details string `json:"details"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if !slices.Contains(reportReasons, request.Reason) || len(request.Details) > reportDetailsLength {
+			respJsonBadRequest(w, r, errorInvalidReport)
+			return
+		}
+		if report, err = config.DBQueries.CreateReport(
+			r.Context(),
+			database.CreateReportParams{
+				ChirpID:    chirp.ID,
+				ReporterID: uuid.NullUUID{UUID: userId, Valid: true},
+				Reason:     request.Reason,
+				Details:    request.Details,
+			},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonReportCreated(w, r, report)
+	}
+}
+
+func HandlerGetAdminReports(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var reports []database.Report
+		if reports, err = config.DBQueries.GetReportsOpen(r.Context()); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonReports(w, r, reports)
+	})
+}
+
+func HandlerPostAdminReportsId(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var moderatorId, reportId uuid.UUID
+		var report database.Report
+		var chirp database.Chirp
+		var decision database.ModerationDecision
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respJsonUnauthorized(w, r, errorMissingToken)
+			return
+		}
+		if moderatorId, err = config.validateToken(
+			r.Context(),
+			token,
+			empty,
+		); errors.Is(err, errInsufficientScope) {
+			respJsonForbidden(w, r, errorInsufficientScope)
+			return
+		} else if err != nil {
+			respJsonUnauthorized(w, r, errorInvalidToken)
+			return
+		}
+		if reportId, err = uuid.Parse(r.PathValue("id")); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if report, err = config.DBQueries.GetReport(
+			r.Context(),
+			reportId,
+		); err != nil || report.ID == uuid.Nil || report.Status != reportStatusOpen {
+			respPlainNotFound(w, r)
+			return
+		}
+		if chirp, err = config.DBQueries.GetChirp(
+			r.Context(),
+			report.ChirpID,
+		); err != nil || chirp.ID == uuid.Nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		request := struct {
+			Action string `json:"action"`
+			Note   string `json:"note"`
+		}{}
+		if json.NewDecoder(r.Body).Decode(&request) != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		if !slices.Contains(moderationActions, request.Action) {
+			respJsonBadRequest(w, r, errorInvalidModerationAction)
+			return
+		}
+		if decision, err = config.moderate(
+			r.Context(),
+			report,
+			chirp,
+			database.CreateModerationDecisionParams{
+				ReportID:    uuid.NullUUID{UUID: report.ID, Valid: true},
+				ChirpID:     uuid.NullUUID{UUID: chirp.ID, Valid: true},
+				AuthorID:    chirp.UserID,
+				ModeratorID: uuid.NullUUID{UUID: moderatorId, Valid: true},
+				Action:      request.Action,
+				Note:        request.Note,
+			},
+		); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonModerationDecisionCreated(w, r, decision)
+	})
+}
+
+func HandlerGetAdminDecisions(config *ApiConfig) http.HandlerFunc {
+	return config.middleRole(roleModerator, func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var decisions []database.ModerationDecision
+		if decisions, err = config.DBQueries.GetModerationDecisions(r.Context()); err != nil {
+			respJsonBadRequest(w, r, errorSomethingWentWrong)
+			return
+		}
+		respJsonModerationDecisions(w, r, decisions)
+	})
+}
+
+func HandlerGetLCode(config *ApiConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var link database.ChirpLink
+		if link, err = config.getPublicChirpLink(
+			r.Context(),
+			r.PathValue("code"),
+		); err != nil || link.ID == uuid.Nil {
+			respPlainNotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, link.Url, http.StatusFound)
+	}
+}
+
 func HandlerPostApiPolkaWebhooks(config *ApiConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var err error
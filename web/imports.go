@@ -0,0 +1,190 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mamatb/Chirpy/database"
+)
+
+type ImportResult struct {
+	Imported int           `json:"imported"`
+	Errors   []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Line    int              `json:"line"`
+	Error   string           `json:"error"`
+	Reasons []ChirpRejection `json:"reasons,omitempty"`
+}
+
+type importRecord struct {
+	line      int
+	body      string
+	createdAt string
+	err       error
+}
+
+// ImportChirps runs every record through the same pipeline as new chirps and
+// stores the ones that pass in a single transaction, so a failure on our side
+// imports nothing rather than part of the file.
+func (c *ApiConfig) ImportChirps(ctx context.Context, user database.User, format string,
+	reader io.Reader) (ImportResult, error) {
+	var err error
+	var tx *sql.Tx
+	var records []importRecord
+	var links []database.ChirpLink
+	switch format {
+	case importFormatCsv:
+		records, err = readImportCsv(reader)
+	case importFormatNdjson:
+		records, err = readImportNdjson(reader)
+	default:
+		err = errors.New(errorInvalidImportFormat)
+	}
+	if err != nil {
+		return ImportResult{}, err
+	}
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return ImportResult{}, err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	result, batch := ImportResult{Errors: []ImportError{}}, newChirpBatch()
+	for _, record := range records {
+		if importError, chirpLinks, err := c.importChirp(ctx, queries, user, batch, record); err != nil {
+			return ImportResult{}, err
+		} else if importError != nil {
+			result.Errors = append(result.Errors, *importError)
+		} else {
+			links = append(links, chirpLinks...)
+			result.Imported++
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return ImportResult{}, err
+	}
+	for _, link := range links {
+		c.enqueueLinkPreview(link)
+	}
+	return result, nil
+}
+
+func (c *ApiConfig) importChirp(ctx context.Context, queries *database.Queries, user database.User,
+	batch *chirpBatch, record importRecord) (*ImportError, []database.ChirpLink, error) {
+	var err error
+	var createdAt time.Time
+	var links []database.ChirpLink
+	if record.err != nil {
+		return &ImportError{Line: record.line, Error: record.err.Error()}, nil, nil
+	}
+	if createdAt, err = time.Parse(time.RFC3339, record.createdAt); err != nil ||
+		createdAt.After(time.Now()) {
+		return &ImportError{Line: record.line, Error: errorInvalidCreatedAt}, nil, nil
+	}
+	draft := ChirpDraft{User: user, Body: record.body, Status: chirpStatusPublished, batch: batch}
+	if err = c.processChirp(ctx, &draft); err != nil {
+		var rejections ChirpRejections
+		if errors.As(err, &rejections) {
+			return &ImportError{
+				Line:    record.line,
+				Error:   rejections.Error(),
+				Reasons: rejections,
+			}, nil, nil
+		}
+		return nil, nil, err
+	}
+	if _, links, err = insertChirp(ctx, queries, draft, createdAt); err != nil {
+		return nil, nil, err
+	}
+	batch.add(draft)
+	return nil, links, nil
+}
+
+// readImportNdjson reports lines over the size limit as bad records of their
+// own instead of giving up on the rest of the file.
+func readImportNdjson(reader io.Reader) ([]importRecord, error) {
+	var records []importRecord
+	lineReader := bufio.NewReaderSize(reader, importMaxLineBytes)
+	for line := 1; ; line++ {
+		text, tooLong, err := lineReader.ReadLine()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if tooLong {
+			for tooLong && err == nil {
+				_, tooLong, err = lineReader.ReadLine()
+			}
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			records = append(records, importRecord{line: line, err: errors.New(errorRecordTooLong)})
+			continue
+		}
+		if len(strings.TrimSpace(string(text))) == 0 {
+			continue
+		}
+		record := struct {
+			Body      string `json:"body"`
+			CreatedAt string `json:"created_at"`
+		}{}
+		if json.Unmarshal(text, &record) != nil {
+			records = append(records, importRecord{line: line, err: errors.New(errorInvalidRecord)})
+			continue
+		}
+		records = append(records, importRecord{
+			line:      line,
+			body:      record.Body,
+			createdAt: record.CreatedAt,
+		})
+	}
+}
+
+func readImportCsv(reader io.Reader) ([]importRecord, error) {
+	var err error
+	var header []string
+	var records []importRecord
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	if header, err = csvReader.Read(); err != nil {
+		return nil, err
+	}
+	bodyIdx := slices.Index(header, importColumnBody)
+	createdAtIdx := slices.Index(header, importColumnCreatedAt)
+	if bodyIdx < 0 || createdAtIdx < 0 {
+		return nil, errors.New(errorInvalidImportHeader)
+	}
+	for {
+		fields, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			records = append(records, importRecord{line: parseErr.Line, err: errors.New(errorInvalidRecord)})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		line, _ := csvReader.FieldPos(0)
+		if len(fields) <= max(bodyIdx, createdAtIdx) {
+			records = append(records, importRecord{line: line, err: errors.New(errorInvalidRecord)})
+			continue
+		}
+		records = append(records, importRecord{
+			line:      line,
+			body:      fields[bodyIdx],
+			createdAt: fields[createdAtIdx],
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestReadImportNdjson(t *testing.T) {
+	errInvalid, errTooLong := errors.New(errorInvalidRecord), errors.New(errorRecordTooLong)
+	long := strings.Repeat("x", importMaxLineBytes)
+	tests := map[string][]importRecord{
+		`{"body": "first", "created_at": "2020-01-02T03:04:05Z"}`: {
+			{line: 1, body: "first", createdAt: "2020-01-02T03:04:05Z"},
+		},
+		"{\"body\": \"first\", \"created_at\": \"2020-01-02T03:04:05Z\"}\n\nnot json\n" +
+			"{\"body\": \"second\", \"created_at\": \"2020-01-03T03:04:05Z\"}\n": {
+			{line: 1, body: "first", createdAt: "2020-01-02T03:04:05Z"},
+			{line: 3, err: errInvalid},
+			{line: 4, body: "second", createdAt: "2020-01-03T03:04:05Z"},
+		},
+		"{\"body\": \"" + long + "\"}\n{\"body\": \"second\", \"created_at\": \"2020-01-03T03:04:05Z\"}\n": {
+			{line: 1, err: errTooLong},
+			{line: 2, body: "second", createdAt: "2020-01-03T03:04:05Z"},
+		},
+		"{\"body\": \"" + long + "\"}": {
+			{line: 1, err: errTooLong},
+		},
+	}
+	for input, want := range tests {
+		if output, err := readImportNdjson(strings.NewReader(input)); err != nil ||
+			!slices.EqualFunc(output, want, equalImportRecords) {
+			t.Errorf(
+				"readImportNdjson(%.60q) = (%+v, %v), want (%+v, nil)",
+				input, output, err, want,
+			)
+		}
+	}
+}
+
+func TestReadImportCsv(t *testing.T) {
+	errInvalid := errors.New(errorInvalidRecord)
+	tests := map[string][]importRecord{
+		"created_at,body\n" +
+			"2020-01-02T03:04:05Z,first\n" +
+			"2020-01-03T03:04:05Z,\"multi\nline\"\n" +
+			"2020-01-04T03:04:05Z\n" +
+			"2020-01-05T03:04:05Z,last\n": {
+			{line: 2, body: "first", createdAt: "2020-01-02T03:04:05Z"},
+			{line: 3, body: "multi\nline", createdAt: "2020-01-03T03:04:05Z"},
+			{line: 5, err: errInvalid},
+			{line: 6, body: "last", createdAt: "2020-01-05T03:04:05Z"},
+		},
+		"body,created_at\n\"unterminated,2020-01-02T03:04:05Z\n": {
+			{line: 2, err: errInvalid},
+		},
+	}
+	for input, want := range tests {
+		if output, err := readImportCsv(strings.NewReader(input)); err != nil ||
+			!slices.EqualFunc(output, want, equalImportRecords) {
+			t.Errorf(
+				"readImportCsv(%q) = (%+v, %v), want (%+v, nil)",
+				input, output, err, want,
+			)
+		}
+	}
+
+	for _, input := range []string{empty, "text,date\nhello,2020\n"} {
+		if output, err := readImportCsv(strings.NewReader(input)); err == nil {
+			t.Errorf(
+				"readImportCsv(%q) = (%+v, nil), want (nil, error)",
+				input, output,
+			)
+		}
+	}
+}
+
+func TestImportChirps(t *testing.T) {
+	user, now := database.User{ID: uuid.New()}, time.Now()
+	chirp := testResult{
+		columns: []string{"id", "created_at", "updated_at", "body", "user_id", "status", "fingerprint", "spam_score"},
+		rows:    [][]driver.Value{{uuid.NewString(), now, now, "body", user.ID.String(), "published", "", 0.0}},
+	}
+	input := "{\"body\": \"first\", \"created_at\": \"2020-01-02T03:04:05Z\"}\n" +
+		"{\"body\": \"second\", \"created_at\": \"2020-01-03T03:04:05Z\"}\n"
+	db, testDb := newTestDb(map[string]testResult{"CreateChirpImported": chirp})
+	config := ApiConfig{DB: db, DBQueries: database.New(db)}
+	config.RegisterChirpProcessors(testChirpProcessor{name: "x", stage: ChirpStageReject, reject: true})
+	if output, err := config.ImportChirps(
+		context.Background(),
+		user,
+		importFormatNdjson,
+		strings.NewReader(input),
+	); err != nil || output.Imported != 0 || len(output.Errors) != 2 || output.Errors[1].Line != 2 ||
+		output.Errors[1].Reasons[0].Processor != "x" {
+		t.Errorf(
+			"ImportChirps() with a rejecting processor = (%+v, %v), want (2 rejected lines, nil)",
+			output, err,
+		)
+	}
+	if output := testDb.ran(); len(output) != 0 || !testDb.committed {
+		t.Errorf("ImportChirps() with a rejecting processor ran (%v), want (nothing, committed)", output)
+	}
+
+	db, testDb = newTestDb(map[string]testResult{
+		"CreateChirpImported": chirp,
+		"CreateChirpLink":     {err: errors.New("boom")},
+	})
+	config = ApiConfig{DB: db, DBQueries: database.New(db)}
+	config.RegisterChirpProcessors(chirpLinksProcessor{})
+	input = "{\"body\": \"first\", \"created_at\": \"2020-01-02T03:04:05Z\"}\n" +
+		"{\"body\": \"see https://example.com\", \"created_at\": \"2020-01-03T03:04:05Z\"}\n"
+	if output, err := config.ImportChirps(
+		context.Background(),
+		user,
+		importFormatNdjson,
+		strings.NewReader(input),
+	); err == nil || output.Imported != 0 || testDb.committed {
+		t.Errorf(
+			"ImportChirps() with a failing insert = (%+v, %v, committed %t), want (nothing, error, rolled back)",
+			output, err, testDb.committed,
+		)
+	}
+}
+
+func TestImportChirpsBatch(t *testing.T) {
+	user, now := database.User{ID: uuid.New(), CreatedAt: time.Now()}, time.Now()
+	chirp := testResult{
+		columns: []string{"id", "created_at", "updated_at", "body", "user_id", "status", "fingerprint", "spam_score"},
+		rows:    [][]driver.Value{{uuid.NewString(), now, now, "body", user.ID.String(), "published", "", 0.0}},
+	}
+	count := testResult{columns: []string{"count"}, rows: [][]driver.Value{{int64(0)}}}
+	tests := map[string][]string{
+		"{\"body\": \"same\", \"created_at\": \"2020-01-02T03:04:05Z\"}\n" +
+			"{\"body\": \"Same!\", \"created_at\": \"2020-01-03T03:04:05Z\"}\n" +
+			"{\"body\": \"other\", \"created_at\": \"2020-01-04T03:04:05Z\"}\n": {
+			chirpStatusPublished, chirpStatusPublished,
+		},
+		"{\"body\": \"first\", \"created_at\": \"2020-01-02T03:04:05Z\"}\n" +
+			"{\"body\": \"second\", \"created_at\": \"2020-01-03T03:04:05Z\"}\n" +
+			"{\"body\": \"third\", \"created_at\": \"2020-01-04T03:04:05Z\"}\n": {
+			chirpStatusPublished, chirpStatusPublished, chirpStatusModeration,
+		},
+	}
+	for input, want := range tests {
+		db, testDb := newTestDb(map[string]testResult{
+			"CreateChirpImported":   chirp,
+			"CountChirpsDuplicates": count,
+			"CountChirpsFromUser":   count,
+		})
+		config := ApiConfig{
+			DB:                 db,
+			DBQueries:          database.New(db),
+			DuplicateWindow:    time.Hour,
+			SpamThreshold:      spamWeightVelocity,
+			SpamNewAccountAge:  time.Hour,
+			SpamNewAccountRate: 2,
+		}
+		config.RegisterChirpProcessors(chirpDuplicateProcessor{config: &config}, chirpSpamProcessor{config: &config})
+		result, err := config.ImportChirps(context.Background(), user, importFormatNdjson, strings.NewReader(input))
+		var output []string
+		for _, query := range testDb.queries {
+			if query.name == "CreateChirpImported" {
+				output = append(output, query.args[3].(string))
+			}
+		}
+		if err != nil || !slices.Equal(output, want) || result.Imported+len(result.Errors) != 3 {
+			t.Errorf("ImportChirps(%.40q) = (%+v, %v) storing (%v), want storing (%v)", input, result, err, output, want)
+		}
+	}
+}
+
+func equalImportRecords(a importRecord, b importRecord) bool {
+	if (a.err == nil) != (b.err == nil) || (a.err != nil && a.err.Error() != b.err.Error()) {
+		return false
+	}
+	return a.line == b.line && a.body == b.body && a.createdAt == b.createdAt
+}
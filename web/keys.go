@@ -0,0 +1,90 @@
+package web
+
+import (
+	"context"
+	"time"
+
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+// RotateSigningKeys drops expired keys, creates the next one once the live
+// key is within the overlap of retiring, and reloads the key set so every
+// instance signs and publishes the same keys.
+func (c *ApiConfig) RotateSigningKeys(ctx context.Context) error {
+	var err error
+	var rows []database.SigningKey
+	if err = c.DBQueries.DeleteSigningKeysExpired(ctx); err != nil {
+		return err
+	}
+	if rows, err = c.DBQueries.GetSigningKeys(ctx); err != nil {
+		return err
+	}
+	if c.Keys.Algorithm() != auth.AlgorithmHs256 {
+		next := time.Now()
+		for _, row := range rows {
+			if row.Algorithm == c.Keys.Algorithm() && row.RetiresAt.After(next) {
+				next = row.RetiresAt
+			}
+		}
+		if time.Until(next) <= c.JwtKeyOverlap {
+			var row database.SigningKey
+			if row, err = c.createSigningKey(ctx, next); err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+	}
+	keys := make([]auth.SigningKey, 0, len(rows))
+	for _, row := range rows {
+		var der string
+		var key auth.SigningKey
+		if der, err = auth.Decrypt(row.PrivateKey, c.EncryptionKey); err != nil {
+			return err
+		}
+		if key, err = auth.ParseSigningKey(
+			row.Algorithm,
+			[]byte(der),
+			row.RetiresAt,
+			row.ExpiresAt,
+		); err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+	c.Keys.SetKeys(keys)
+	return nil
+}
+
+// createSigningKey makes a key that takes over at start, when the live one
+// retires, so it is published a whole overlap before it signs anything.
+func (c *ApiConfig) createSigningKey(ctx context.Context, start time.Time) (database.SigningKey, error) {
+	var err error
+	var der []byte
+	var encrypted string
+	var key auth.SigningKey
+	retiresAt := start.Add(c.JwtKeyRotation)
+	if key, err = auth.NewSigningKey(
+		c.Keys.Algorithm(),
+		retiresAt,
+		retiresAt.Add(c.JwtKeyOverlap),
+	); err != nil {
+		return database.SigningKey{}, err
+	}
+	if der, err = auth.MarshalSigningKey(key); err != nil {
+		return database.SigningKey{}, err
+	}
+	if encrypted, err = auth.Encrypt(string(der), c.EncryptionKey); err != nil {
+		return database.SigningKey{}, err
+	}
+	return c.DBQueries.CreateSigningKey(
+		ctx,
+		database.CreateSigningKeyParams{
+			ID:         key.Id,
+			Algorithm:  key.Algorithm,
+			PrivateKey: encrypted,
+			RetiresAt:  key.RetiresAt,
+			ExpiresAt:  key.ExpiresAt,
+		},
+	)
+}
@@ -0,0 +1,267 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"html"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+type linkPreview struct {
+	Title       string
+	Description string
+	ImageUrl    string
+	SiteName    string
+}
+
+func (c *ApiConfig) StartLinkPreviews(ctx context.Context, workers int) error {
+	var err error
+	var pending []database.ChirpLink
+	c.linkClient = newLinkClient(false)
+	c.linkPreviews = make(chan database.ChirpLink, linkQueueLength)
+	for range workers {
+		go func() {
+			for link := range c.linkPreviews {
+				c.updateLinkPreview(ctx, link)
+			}
+		}()
+	}
+	if pending, err = c.DBQueries.GetChirpLinksPending(ctx); err != nil {
+		return err
+	}
+	for _, link := range pending {
+		c.enqueueLinkPreview(link)
+	}
+	return nil
+}
+
+func (c *ApiConfig) enqueueLinkPreview(link database.ChirpLink) {
+	select {
+	case c.linkPreviews <- link:
+	default: // queue full or not started, picked up again on next start
+	}
+}
+
+func (c *ApiConfig) updateLinkPreview(ctx context.Context, link database.ChirpLink) {
+	fetchCtx, cancel := context.WithTimeout(ctx, linkFetchTimeout)
+	defer cancel()
+	params := database.UpdateChirpLinkPreviewParams{ID: link.ID}
+	if preview, err := fetchLinkPreview(fetchCtx, c.linkClient, link.Url); err != nil {
+		params.FetchError = sql.NullString{String: err.Error(), Valid: true}
+	} else {
+		params.Title = nullString(preview.Title)
+		params.Description = nullString(preview.Description)
+		params.ImageUrl = nullString(preview.ImageUrl)
+		params.SiteName = nullString(preview.SiteName)
+	}
+	if err := c.DBQueries.UpdateChirpLinkPreview(ctx, params); err != nil {
+		log.Print(err)
+	}
+}
+
+// createChirpLinks stores the links of a chirp; previews are only queued by
+// the caller once they are committed.
+func createChirpLinks(ctx context.Context, queries *database.Queries, chirp database.Chirp,
+	urls []string) ([]database.ChirpLink, error) {
+	var links []database.ChirpLink
+	for urlIdx, url := range urls {
+		var err error
+		var code string
+		var link database.ChirpLink
+		if code, err = makeLinkCode(); err != nil {
+			return nil, err
+		}
+		if link, err = queries.CreateChirpLink(
+			ctx,
+			database.CreateChirpLinkParams{
+				ChirpID:  chirp.ID,
+				Position: int32(urlIdx),
+				Url:      url,
+				Code:     code,
+			},
+		); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+func (c *ApiConfig) getChirpLinks(ctx context.Context,
+	chirps []database.Chirp) (map[uuid.UUID][]jsonLink, error) {
+	var err error
+	var links []database.ChirpLink
+	chirpIds := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIds = append(chirpIds, chirp.ID)
+	}
+	if links, err = c.DBQueries.GetChirpLinksFromChirps(ctx, chirpIds); err != nil {
+		return nil, err
+	}
+	chirpLinks := map[uuid.UUID][]jsonLink{}
+	for _, link := range links {
+		chirpLinks[link.ChirpID] = append(chirpLinks[link.ChirpID], c.jsonLinks(link)...)
+	}
+	return chirpLinks, nil
+}
+
+// getPublicChirpLink finds a short link only while anyone can read the chirp
+// it is in: published, and not by a suspended or deactivated author.
+func (c *ApiConfig) getPublicChirpLink(ctx context.Context, code string) (database.ChirpLink, error) {
+	var err error
+	var link database.ChirpLink
+	var chirp database.Chirp
+	var author database.User
+	if link, err = c.DBQueries.GetChirpLinkFromCode(ctx, code); err != nil {
+		return link, err
+	}
+	if chirp, err = c.DBQueries.GetChirp(ctx, link.ChirpID); err != nil {
+		return link, err
+	}
+	if chirp.Status != chirpStatusPublished {
+		return link, sql.ErrNoRows
+	}
+	if chirp.UserID.Valid {
+		if author, err = c.DBQueries.GetUserFromId(ctx, chirp.UserID.UUID); err != nil {
+			return link, err
+		}
+		if !isUserActive(author) {
+			return link, sql.ErrNoRows
+		}
+	}
+	return link, nil
+}
+
+func (c *ApiConfig) jsonLinks(links ...database.ChirpLink) []jsonLink {
+	linksJson := []jsonLink{}
+	for _, link := range links {
+		linkJson := jsonLink{
+			Url:      link.Url,
+			ShortUrl: c.BaseUrl + linkPath + link.Code,
+		}
+		if link.FetchedAt.Valid && !link.FetchError.Valid {
+			linkJson.Preview = &jsonLinkPreview{
+				Title:       link.Title.String,
+				Description: link.Description.String,
+				ImageUrl:    link.ImageUrl.String,
+				SiteName:    link.SiteName.String,
+			}
+		}
+		linksJson = append(linksJson, linkJson)
+	}
+	return linksJson
+}
+
+func newLinkClient(allowPrivate bool) *http.Client {
+	dialer := net.Dialer{
+		Timeout: linkFetchTimeout,
+		Control: func(_ string, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || (!allowPrivate && !isPublicIp(ip)) {
+				return errors.New(errorLinkForbiddenAddress)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: linkFetchTimeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   linkFetchTimeout,
+			ResponseHeaderTimeout: linkFetchTimeout,
+		},
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			if len(via) >= linkMaxRedirects {
+				return errors.New(errorLinkTooManyRedirects)
+			}
+			if r.URL.Scheme != "http" && r.URL.Scheme != "https" {
+				return errors.New(errorLinkForbiddenAddress)
+			}
+			return nil
+		},
+	}
+}
+
+func isPublicIp(ip net.IP) bool {
+	_, sharedAddressSpace, _ := net.ParseCIDR("100.64.0.0/10")
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+func fetchLinkPreview(ctx context.Context, client *http.Client, url string) (linkPreview, error) {
+	var err error
+	var request *http.Request
+	var response *http.Response
+	var body []byte
+	if request, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return linkPreview{}, err
+	}
+	request.Header.Set(headerUserAgent, linkUserAgent)
+	if response, err = client.Do(request); err != nil {
+		return linkPreview{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return linkPreview{}, errors.New(response.Status)
+	}
+	if !strings.HasPrefix(response.Header.Get(headerContentType), "text/html") {
+		return linkPreview{}, errors.New(errorLinkNotHtml)
+	}
+	if body, err = io.ReadAll(io.LimitReader(response.Body, linkMaxBytes)); err != nil {
+		return linkPreview{}, err
+	}
+	preview, meta := linkPreview{}, map[string]string{}
+	for _, tag := range regexMetaTag.FindAllString(string(body), -1) {
+		var key, content string
+		for _, attribute := range regexHtmlAttribute.FindAllStringSubmatch(tag, -1) {
+			switch value := attribute[2] + attribute[3]; strings.ToLower(attribute[1]) {
+			case "property", "name":
+				key = strings.ToLower(value)
+			case "content":
+				content = html.UnescapeString(value)
+			}
+		}
+		if _, ok := meta[key]; !ok && len(key) > 0 {
+			meta[key] = strings.TrimSpace(content)
+		}
+	}
+	preview.Title, preview.Description = meta["og:title"], meta["og:description"]
+	preview.ImageUrl, preview.SiteName = meta["og:image"], meta["og:site_name"]
+	if len(preview.Title) == 0 {
+		if title := regexTitleTag.FindStringSubmatch(string(body)); title != nil {
+			preview.Title = strings.TrimSpace(html.UnescapeString(title[1]))
+		}
+	}
+	if len(preview.Description) == 0 {
+		preview.Description = meta["description"]
+	}
+	return preview, nil
+}
+
+func makeLinkCode() (string, error) {
+	code := make([]byte, linkCodeLength)
+	if _, err := rand.Read(code); err != nil {
+		return empty, err
+	}
+	return base64.RawURLEncoding.EncodeToString(code), nil
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: len(value) > 0}
+}
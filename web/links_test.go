@@ -0,0 +1,128 @@
+package web
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestFetchLinkPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/og":
+			w.Header().Set(headerContentType, contentTypeHtml)
+			w.Write([]byte(`<html><head>
+				<meta property="og:title" content="Chirpy &amp; friends">
+				<meta content='A tiny social network' property='og:description'/>
+				<meta property="og:image" content="https://example.com/logo.png">
+				<meta property="og:site_name" content="Chirpy">
+				</head></html>`))
+		case "/title":
+			w.Header().Set(headerContentType, contentTypeHtml)
+			w.Write([]byte(`<html><head><title> Just a title </title>` +
+				`<meta name="description" content="Plain description"></head></html>`))
+		case "/large":
+			w.Header().Set(headerContentType, contentTypeHtml)
+			w.Write([]byte(strings.Repeat(" ", linkMaxBytes) + `<title>Too far</title>`))
+		case "/redirect":
+			http.Redirect(w, r, "/redirect", http.StatusFound)
+		case "/plain":
+			w.Header().Set(headerContentType, contentTypePlain)
+			w.Write([]byte(httpOkPlain))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := newLinkClient(true)
+	testsOk := map[string]linkPreview{
+		"/og": {
+			Title:       "Chirpy & friends",
+			Description: "A tiny social network",
+			ImageUrl:    "https://example.com/logo.png",
+			SiteName:    "Chirpy",
+		},
+		"/title": {Title: "Just a title", Description: "Plain description"},
+		"/large": {},
+	}
+	testsErr := []string{"/redirect", "/plain", "/missing"}
+	for input, want := range testsOk {
+		if output, err := fetchLinkPreview(context.Background(), client, server.URL+input); err != nil ||
+			output != want {
+			t.Errorf(
+				"fetchLinkPreview(\"%s\") = (%+v, %v), want (%+v, nil)",
+				input, output, err, want,
+			)
+		}
+	}
+	for _, input := range testsErr {
+		if output, err := fetchLinkPreview(context.Background(), client, server.URL+input); err == nil {
+			t.Errorf(
+				"fetchLinkPreview(\"%s\") = (%+v, nil), want (error)",
+				input, output,
+			)
+		}
+	}
+	if output, err := fetchLinkPreview(
+		context.Background(),
+		newLinkClient(false),
+		server.URL+"/og",
+	); err == nil || !strings.Contains(err.Error(), errorLinkForbiddenAddress) {
+		t.Errorf(
+			"fetchLinkPreview(\"%s\") = (%+v, %v), want (%s)",
+			"/og", output, err, errorLinkForbiddenAddress,
+		)
+	}
+}
+
+func TestGetLCode(t *testing.T) {
+	now, chirpId, authorId := time.Now(), uuid.New(), uuid.New()
+	link := testResult{
+		columns: []string{
+			"id", "created_at", "updated_at", "chirp_id", "position", "url", "code", "title",
+			"description", "image_url", "site_name", "fetched_at", "fetch_error",
+		},
+		rows: [][]driver.Value{{
+			uuid.NewString(), now, now, chirpId.String(), int64(0), "https://example.com", "abcdef", nil,
+			nil, nil, nil, nil, nil,
+		}},
+	}
+	suspended := testUserResult(authorId)
+	suspended.rows[0][6] = now
+	tests := map[string]int{
+		chirpStatusPublished:  http.StatusFound,
+		chirpStatusHidden:     http.StatusNotFound,
+		chirpStatusModeration: http.StatusNotFound,
+		"suspended":           http.StatusNotFound,
+	}
+	for input, want := range tests {
+		status, author := input, testUserResult(authorId)
+		if input == "suspended" {
+			status, author = chirpStatusPublished, suspended
+		}
+		db, _ := newTestDb(map[string]testResult{
+			"GetChirpLinkFromCode": link,
+			"GetChirp": {
+				columns: []string{"id", "created_at", "updated_at", "body", "user_id", "status", "fingerprint", "spam_score"},
+				rows:    [][]driver.Value{{chirpId.String(), now, now, "body", authorId.String(), status, empty, 0.0}},
+			},
+			"GetUserFromId": author,
+		})
+		config := ApiConfig{DBQueries: database.New(db)}
+		request := httptest.NewRequest(http.MethodGet, "/l/abcdef", nil)
+		request.SetPathValue("code", "abcdef")
+		recorder := httptest.NewRecorder()
+		HandlerGetLCode(&config)(recorder, request)
+		if output := recorder.Code; output != want {
+			t.Errorf("GET /l/abcdef of a %s chirp = (%d), want (%d)", input, output, want)
+		}
+	}
+}
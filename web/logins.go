@@ -0,0 +1,176 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+var (
+	errInvalidCredentials = errors.New(errorInvalidEmailPassword)
+	errInvalidMfaCode     = errors.New(errorInvalidMfaCode)
+	errLoginThrottled     = errors.New(errorTooManyLoginAttempts)
+)
+
+func (c *ApiConfig) PurgeLoginFailures(ctx context.Context) error {
+	if err := c.DBQueries.DeleteLoginFailuresExpired(ctx, time.Now().Add(-loginFailureWindow)); err != nil {
+		return err
+	}
+	return c.DBQueries.DeleteMfaFailuresExpired(ctx, time.Now().Add(-loginFailureWindow))
+}
+
+// checkPassword verifies a login, backing off exponentially on repeated
+// failures for the email and for the address they come from. Each attempt is
+// recorded as a failure before the password is compared and only forgiven
+// once it proves right, so concurrent attempts cannot all slip in under the
+// limit. Unknown emails are checked against a dummy hash and throttled just
+// like real ones, so neither timing nor lockouts tell whether an account
+// exists.
+func (c *ApiConfig) checkPassword(ctx context.Context, email string, password string,
+	ipAddress string) (database.User, time.Duration, error) {
+	var err error
+	var retryAfter time.Duration
+	var user database.User
+	var attempt database.LoginFailure
+	if attempt, err = c.DBQueries.CreateLoginFailure(
+		ctx,
+		database.CreateLoginFailureParams{
+			Email:     email,
+			IpAddress: ipAddress,
+		},
+	); err != nil {
+		return user, 0, err
+	}
+	if retryAfter, err = c.loginRetryAfter(ctx, attempt); err != nil {
+		return user, 0, err
+	}
+	if retryAfter > 0 {
+		// the password was never tried, so the attempt does not count
+		if err = c.DBQueries.DeleteLoginFailure(ctx, attempt.ID); err != nil {
+			return user, 0, err
+		}
+		return user, retryAfter, errLoginThrottled
+	}
+	if user, err = c.DBQueries.GetUser(ctx, email); errors.Is(err, sql.ErrNoRows) {
+		err = auth.ValidateDummyHash(password)
+	} else if err != nil {
+		return user, 0, err
+	} else {
+		err = auth.ValidateHash(password, user.HashedPassword)
+	}
+	if err != nil {
+		return database.User{}, 0, errInvalidCredentials
+	}
+	if err = c.DBQueries.DeleteLoginFailuresFromEmail(ctx, email); err != nil {
+		return user, 0, err
+	}
+	return user, 0, nil
+}
+
+// checkMfa verifies the second factor of a login with the same backoff as
+// checkPassword, counted against the user rather than the email. A correct
+// password clears login failures, so every new challenge would otherwise
+// bring a fresh set of guesses at the code.
+func (c *ApiConfig) checkMfa(ctx context.Context, user database.User, code string,
+	recoveryCode string) (time.Duration, error) {
+	var err error
+	var failures []time.Time
+	var attempt database.MfaFailure
+	now := time.Now()
+	if attempt, err = c.DBQueries.CreateMfaFailure(ctx, user.ID); err != nil {
+		return 0, err
+	}
+	if failures, err = c.DBQueries.GetMfaFailuresFromUser(
+		ctx,
+		database.GetMfaFailuresFromUserParams{
+			UserID:    user.ID,
+			CreatedAt: now.Add(-loginFailureWindow),
+			ID:        attempt.ID,
+		},
+	); err != nil {
+		return 0, err
+	}
+	if retryAfter := loginBackoff(failures, loginMfaFailures, now); retryAfter > 0 {
+		if err = c.DBQueries.DeleteMfaFailure(ctx, attempt.ID); err != nil {
+			return 0, err
+		}
+		return retryAfter, errLoginThrottled
+	}
+	if c.verifyMfa(ctx, user, code, recoveryCode) != nil {
+		return 0, errInvalidMfaCode
+	}
+	return 0, c.DBQueries.DeleteMfaFailuresFromUser(ctx, user.ID)
+}
+
+// unlockUser lifts every backoff standing between a user and logging in:
+// failures for their email, failures from the addresses those came from and
+// wrong MFA codes.
+func (c *ApiConfig) unlockUser(ctx context.Context, user database.User) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.DeleteLoginFailuresFromEmailAndIpAddresses(ctx, user.Email); err != nil {
+		return err
+	}
+	if err = queries.DeleteMfaFailuresFromUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loginRetryAfter is how long an attempt has to wait for the failures that
+// came before it, from its email and from its address.
+func (c *ApiConfig) loginRetryAfter(ctx context.Context, attempt database.LoginFailure) (time.Duration, error) {
+	var err error
+	var fromEmail, fromIpAddress []time.Time
+	now := time.Now()
+	if fromEmail, err = c.DBQueries.GetLoginFailuresFromEmail(
+		ctx,
+		database.GetLoginFailuresFromEmailParams{
+			Email:     attempt.Email,
+			CreatedAt: now.Add(-loginFailureWindow),
+			ID:        attempt.ID,
+		},
+	); err != nil {
+		return 0, err
+	}
+	if fromIpAddress, err = c.DBQueries.GetLoginFailuresFromIpAddress(
+		ctx,
+		database.GetLoginFailuresFromIpAddressParams{
+			IpAddress: attempt.IpAddress,
+			CreatedAt: now.Add(-loginFailureWindow),
+			ID:        attempt.ID,
+		},
+	); err != nil {
+		return 0, err
+	}
+	return max(
+		loginBackoff(fromEmail, loginEmailFailures, now),
+		loginBackoff(fromIpAddress, loginIpAddressFailures, now),
+	), nil
+}
+
+// loginBackoff is how long is left to wait after failures, newest first. The
+// first free ones cost nothing, then the wait doubles from loginBackoffBase
+// with every further one up to loginBackoffMax, rounded up to the second.
+func loginBackoff(failures []time.Time, free int, now time.Time) time.Duration {
+	if len(failures) < free {
+		return 0
+	}
+	backoff := loginBackoffMax
+	if doublings := len(failures) - free; doublings < loginBackoffDoublings {
+		backoff = min(loginBackoffBase<<doublings, loginBackoffMax)
+	}
+	if wait := failures[0].Add(backoff).Sub(now); wait > 0 {
+		return (wait + time.Second - 1).Truncate(time.Second)
+	}
+	return 0
+}
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+type loginBackoffInput struct {
+	failures int
+	age      time.Duration
+}
+
+func TestLoginBackoff(t *testing.T) {
+	now := time.Now()
+	tests := map[loginBackoffInput]time.Duration{
+		{0, 0}:                                         0,
+		{loginEmailFailures - 1, 0}:                    0,
+		{loginEmailFailures, 0}:                        loginBackoffBase,
+		{loginEmailFailures + 2, 0}:                    4 * loginBackoffBase,
+		{loginEmailFailures + 40, 0}:                   loginBackoffMax,
+		{loginEmailFailures, 30 * time.Second}:         30 * time.Second,
+		{loginEmailFailures, 59500 * time.Millisecond}: time.Second,
+		{loginEmailFailures, 2 * loginBackoffBase}:     0,
+	}
+	for input, want := range tests {
+		failures := make([]time.Time, input.failures)
+		for failureIdx := range failures {
+			failures[failureIdx] = now.Add(-input.age - time.Duration(failureIdx)*time.Second)
+		}
+		if output := loginBackoff(failures, loginEmailFailures, now); output != want {
+			t.Errorf("loginBackoff(%+v) = (%s), want (%s)", input, output, want)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	now := time.Now()
+	hash, err := auth.HashPassword("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := testUserResult(uuid.New())
+	user.rows[0][4] = hash
+	failures := testResult{columns: []string{"created_at"}}
+	for range loginEmailFailures {
+		failures.rows = append(failures.rows, []driver.Value{now})
+	}
+	attempt := testResult{
+		columns: []string{"id", "created_at", "email", "ip_address"},
+		rows:    [][]driver.Value{{uuid.NewString(), now, "walt@example.com", "192.0.2.1"}},
+	}
+	tests := map[string][]string{
+		"password": {"CreateLoginFailure", "GetLoginFailuresFromEmail", "GetLoginFailuresFromIpAddress",
+			"GetUser", "DeleteLoginFailuresFromEmail"},
+		"wrong": {"CreateLoginFailure", "GetLoginFailuresFromEmail", "GetLoginFailuresFromIpAddress",
+			"GetUser"},
+		"throttled": {"CreateLoginFailure", "GetLoginFailuresFromEmail", "GetLoginFailuresFromIpAddress",
+			"DeleteLoginFailure"},
+	}
+	wantErrs := map[string]error{
+		"password":  nil,
+		"wrong":     errInvalidCredentials,
+		"throttled": errLoginThrottled,
+	}
+	for input, want := range tests {
+		results := map[string]testResult{"CreateLoginFailure": attempt, "GetUser": user}
+		if input == "throttled" {
+			results["GetLoginFailuresFromEmail"] = failures
+		}
+		db, testDb := newTestDb(results)
+		config := ApiConfig{DBQueries: database.New(db)}
+		_, _, err := config.checkPassword(context.Background(), "walt@example.com", input, "192.0.2.1")
+		if output := testDb.ran(); !slices.Equal(output, want) || !errors.Is(err, wantErrs[input]) {
+			t.Errorf(
+				"checkPassword(\"%s\") = (%v) after (%v), want (%v) after (%v)",
+				input, err, output, wantErrs[input], want,
+			)
+		}
+	}
+}
+
+func TestCheckMfa(t *testing.T) {
+	now := time.Now()
+	key := make([]byte, 32)
+	secret, err := auth.MakeTotpSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := auth.Encrypt(secret, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := auth.MakeTotpCode(secret, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := database.User{
+		ID:            uuid.New(),
+		TotpSecret:    nullString(encrypted),
+		TotpEnabledAt: sql.NullTime{Time: now, Valid: true},
+	}
+	failures := testResult{columns: []string{"created_at"}}
+	for range loginMfaFailures {
+		failures.rows = append(failures.rows, []driver.Value{now})
+	}
+	attempt := testResult{
+		columns: []string{"id", "created_at", "user_id"},
+		rows:    [][]driver.Value{{uuid.NewString(), now, user.ID.String()}},
+	}
+	tests := map[string][]string{
+		code:        {"CreateMfaFailure", "GetMfaFailuresFromUser", "UpdateUserTotpLastStep", "DeleteMfaFailuresFromUser"},
+		"000000x":   {"CreateMfaFailure", "GetMfaFailuresFromUser"},
+		"throttled": {"CreateMfaFailure", "GetMfaFailuresFromUser", "DeleteMfaFailure"},
+	}
+	wantErrs := map[string]error{
+		code:        nil,
+		"000000x":   errInvalidMfaCode,
+		"throttled": errLoginThrottled,
+	}
+	for input, want := range tests {
+		results := map[string]testResult{
+			"CreateMfaFailure":       attempt,
+			"UpdateUserTotpLastStep": {affected: 1},
+		}
+		if input == "throttled" {
+			results["GetMfaFailuresFromUser"] = failures
+		}
+		db, testDb := newTestDb(results)
+		config := ApiConfig{DBQueries: database.New(db), EncryptionKey: key}
+		retryAfter, err := config.checkMfa(context.Background(), user, input, empty)
+		if output := testDb.ran(); !slices.Equal(output, want) || !errors.Is(err, wantErrs[input]) ||
+			(retryAfter > 0) != (input == "throttled") {
+			t.Errorf(
+				"checkMfa(\"%s\") = (%s, %v) after (%v), want (%v) after (%v)",
+				input, retryAfter, err, output, wantErrs[input], want,
+			)
+		}
+	}
+}
+
+func TestUnlockUser(t *testing.T) {
+	db, testDb := newTestDb(map[string]testResult{})
+	config := ApiConfig{DB: db, DBQueries: database.New(db)}
+	want := []string{"DeleteLoginFailuresFromEmailAndIpAddresses", "DeleteMfaFailuresFromUser"}
+	user := database.User{ID: uuid.New(), Email: "walt@example.com"}
+	if err := config.unlockUser(context.Background(), user); err != nil || !slices.Equal(testDb.ran(), want) ||
+		!testDb.committed {
+		t.Errorf("unlockUser() = (%v, committed %t) after (%v), want after (%v)", err, testDb.committed, testDb.ran(), want)
+	}
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+// sendMagicLink runs outside the request so that unknown addresses and rate
+// limited ones answer exactly like real ones
+func (c *ApiConfig) sendMagicLink(ctx context.Context, email string, fingerprint string) error {
+	var err error
+	var token string
+	var user database.User
+	var recent int64
+	if user, err = c.DBQueries.GetUser(ctx, email); err != nil {
+		return nil
+	}
+	if recent, err = c.DBQueries.CountMagicLinksFromUser(
+		ctx,
+		database.CountMagicLinksFromUserParams{
+			UserID:    user.ID,
+			CreatedAt: time.Now().Add(-magicLinkWindow),
+		},
+	); err != nil || recent >= magicLinkLimit {
+		return err
+	}
+	if token, err = auth.MakeToken(); err != nil {
+		return err
+	}
+	if _, err = c.DBQueries.CreateMagicLink(
+		ctx,
+		database.CreateMagicLinkParams{
+			UserID:      user.ID,
+			TokenHash:   auth.HashToken(token),
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(magicLinkExpiry),
+		},
+	); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set(queryToken, token)
+	return c.Mailer.Send(ctx, Mail{
+		To:      user.Email,
+		Subject: mailMagicLinkSubject,
+		Body:    fmt.Sprintf(mailMagicLinkBody, c.BaseUrl+magicLinkCallbackPath+"?"+query.Encode()),
+	})
+}
+
+func (c *ApiConfig) sendMagicLinkAsync(email string, fingerprint string) {
+	go func() {
+		if err := c.sendMagicLink(context.Background(), email, fingerprint); err != nil {
+			log.Print(err)
+		}
+	}()
+}
+
+// useMagicLink spends the token before looking at the fingerprint, so a link
+// opened from the wrong device cannot be retried from the right one either.
+func (c *ApiConfig) useMagicLink(ctx context.Context, token string, fingerprint string) (database.User, error) {
+	var err error
+	var link database.MagicLink
+	if link, err = c.DBQueries.UpdateMagicLinkUsed(ctx, auth.HashToken(token)); err != nil {
+		return database.User{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(link.Fingerprint), []byte(fingerprint)) != 1 {
+		return database.User{}, errors.New(errorInvalidToken)
+	}
+	return c.DBQueries.GetUserFromId(ctx, link.UserID)
+}
+
+// requestFingerprint ties a magic link to the client that asked for it, by
+// the address and user agent it connected with.
+func requestFingerprint(r *http.Request) string {
+	return auth.HashToken(clientIp(r) + "\n" + r.UserAgent())
+}
@@ -0,0 +1,26 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestFingerprint(t *testing.T) {
+	request := httptest.NewRequest("POST", "/api/login/magic", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+	request.Header.Set(headerUserAgent, "browser")
+	fingerprint := requestFingerprint(request)
+	request.RemoteAddr = "192.0.2.1:4321"
+	if output := requestFingerprint(request); output != fingerprint {
+		t.Errorf("requestFingerprint() changed with the source port")
+	}
+	request.Header.Set(headerUserAgent, "other browser")
+	if output := requestFingerprint(request); output == fingerprint {
+		t.Errorf("requestFingerprint() ignored the user agent")
+	}
+	request.Header.Set(headerUserAgent, "browser")
+	request.RemoteAddr = "192.0.2.2:1234"
+	if output := requestFingerprint(request); output == fingerprint {
+		t.Errorf("requestFingerprint() ignored the address")
+	}
+}
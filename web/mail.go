@@ -0,0 +1,82 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+type SmtpMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m SmtpMailer) Send(_ context.Context, mail Mail) error {
+	var auth smtp.Auth
+	if len(m.Username) > 0 {
+		auth = smtp.PlainAuth(empty, m.Username, m.Password, m.Host)
+	}
+	return smtp.SendMail(
+		net.JoinHostPort(m.Host, strconv.Itoa(m.Port)),
+		auth,
+		m.From,
+		[]string{mail.To},
+		formatMail(m.From, mail),
+	)
+}
+
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m FileMailer) Send(_ context.Context, mail Mail) error {
+	if err := os.MkdirAll(m.Dir, 0o700); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), strings.ReplaceAll(mail.To, "/", "_"))
+	return os.WriteFile(filepath.Join(m.Dir, name), formatMail(m.From, mail), 0o600)
+}
+
+type LogMailer struct {
+	From string
+}
+
+func (m LogMailer) Send(_ context.Context, mail Mail) error {
+	log.Printf("mail from %s to %s: %s\n%s", m.From, mail.To, mail.Subject, mail.Body)
+	return nil
+}
+
+func formatMail(from string, mail Mail) []byte {
+	var message bytes.Buffer
+	header := strings.NewReplacer("\r", empty, "\n", empty)
+	fmt.Fprintf(&message, "From: %s\r\n", header.Replace(from))
+	fmt.Fprintf(&message, "To: %s\r\n", header.Replace(mail.To))
+	fmt.Fprintf(&message, "Subject: %s\r\n", header.Replace(mail.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(mail.Body, "\n", "\r\n"))
+	return message.Bytes()
+}
@@ -0,0 +1,39 @@
+package web
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormatMail(t *testing.T) {
+	message := string(formatMail("no-reply@localhost", Mail{
+		To:      "user@example.com\r\nBcc: victim@example.com",
+		Subject: "Hello",
+		Body:    "line one\nline two",
+	}))
+	if strings.Contains(message, "\r\nBcc:") {
+		t.Errorf("formatMail() allowed header injection:\n%s", message)
+	}
+	for _, want := range []string{
+		"From: no-reply@localhost\r\n",
+		"Subject: Hello\r\n",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("formatMail() missing %q in:\n%s", want, message)
+		}
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	mailer := FileMailer{Dir: t.TempDir(), From: "no-reply@localhost"}
+	if err := mailer.Send(context.Background(), Mail{To: "user@example.com", Body: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(mailer.Dir)
+	if err != nil || len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), "user@example.com.eml") {
+		t.Errorf("FileMailer.Send() wrote %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,232 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+	"github.com/skip2/go-qrcode"
+)
+
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+type totpEnrolment struct {
+	Secret     string
+	OtpauthUri string
+	QrCode     string
+}
+
+func (c *ApiConfig) startTotpEnrolment(ctx context.Context, user database.User) (totpEnrolment, error) {
+	var err error
+	var secret, encrypted string
+	var png []byte
+	if secret, err = auth.MakeTotpSecret(); err != nil {
+		return totpEnrolment{}, err
+	}
+	if encrypted, err = auth.Encrypt(secret, c.EncryptionKey); err != nil {
+		return totpEnrolment{}, err
+	}
+	if err = c.DBQueries.UpdateUserTotpSecret(
+		ctx,
+		database.UpdateUserTotpSecretParams{
+			ID:         user.ID,
+			TotpSecret: nullString(encrypted),
+		},
+	); err != nil {
+		return totpEnrolment{}, err
+	}
+	uri := auth.MakeTotpUri(mfaIssuer, user.Email, secret)
+	if png, err = qrcode.Encode(uri, qrcode.Medium, qrCodeSize); err != nil {
+		return totpEnrolment{}, err
+	}
+	return totpEnrolment{
+		Secret:     secret,
+		OtpauthUri: uri,
+		QrCode:     dataUriPng + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+func (c *ApiConfig) confirmTotp(ctx context.Context, user database.User, code string) ([]string, error) {
+	var err error
+	var tx *sql.Tx
+	var secret string
+	var step int64
+	var recoveryCodes []string
+	if !user.TotpSecret.Valid {
+		return nil, errors.New(errorInvalidMfaCode)
+	}
+	if secret, err = auth.Decrypt(user.TotpSecret.String, c.EncryptionKey); err != nil {
+		return nil, err
+	}
+	if step, err = auth.ValidateTotpCode(secret, code, time.Now()); err != nil {
+		return nil, err
+	}
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.UpdateUserTotpEnabled(
+		ctx,
+		database.UpdateUserTotpEnabledParams{
+			ID:           user.ID,
+			TotpLastStep: step,
+		},
+	); err != nil {
+		return nil, err
+	}
+	if recoveryCodes, err = replaceRecoveryCodes(ctx, queries, user.ID); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, tx.Commit()
+}
+
+func (c *ApiConfig) regenerateRecoveryCodes(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	var err error
+	var tx *sql.Tx
+	var recoveryCodes []string
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if recoveryCodes, err = replaceRecoveryCodes(ctx, c.DBQueries.WithTx(tx), userId); err != nil {
+		return nil, err
+	}
+	return recoveryCodes, tx.Commit()
+}
+
+func (c *ApiConfig) disableTotp(ctx context.Context, userId uuid.UUID) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.UpdateUserTotpSecret(
+		ctx,
+		database.UpdateUserTotpSecretParams{ID: userId},
+	); err != nil {
+		return err
+	}
+	if err = queries.DeleteRecoveryCodesFromUser(ctx, userId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *ApiConfig) createMfaChallenge(ctx context.Context, userId uuid.UUID) (string, database.MfaChallenge, error) {
+	var err error
+	var token string
+	var challenge database.MfaChallenge
+	if token, err = auth.MakeToken(); err != nil {
+		return empty, challenge, err
+	}
+	if challenge, err = c.DBQueries.CreateMfaChallenge(
+		ctx,
+		database.CreateMfaChallengeParams{
+			UserID:    userId,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(mfaChallengeExpiry),
+		},
+	); err != nil {
+		return empty, challenge, err
+	}
+	return token, challenge, nil
+}
+
+// verifyMfa accepts either a totp code, each time step only once, or an
+// unused recovery code.
+func (c *ApiConfig) verifyMfa(ctx context.Context, user database.User, code string,
+	recoveryCode string) error {
+	var err error
+	var secret string
+	var step, rows int64
+	if !user.TotpEnabledAt.Valid || !user.TotpSecret.Valid {
+		return errors.New(errorInvalidMfaCode)
+	}
+	if len(recoveryCode) > 0 {
+		rows, err = c.DBQueries.UpdateRecoveryCodeUsed(
+			ctx,
+			database.UpdateRecoveryCodeUsedParams{
+				UserID:   user.ID,
+				CodeHash: auth.HashToken(normaliseRecoveryCode(recoveryCode)),
+			},
+		)
+	} else {
+		if secret, err = auth.Decrypt(user.TotpSecret.String, c.EncryptionKey); err != nil {
+			return err
+		}
+		if step, err = auth.ValidateTotpCode(secret, code, time.Now()); err != nil {
+			return err
+		}
+		rows, err = c.DBQueries.UpdateUserTotpLastStep(
+			ctx,
+			database.UpdateUserTotpLastStepParams{
+				ID:           user.ID,
+				TotpLastStep: step,
+			},
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New(errorInvalidMfaCode)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, queries *database.Queries, userId uuid.UUID) ([]string, error) {
+	if err := queries.DeleteRecoveryCodesFromUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	recoveryCodes := make([]string, 0, recoveryCodesCount)
+	for range recoveryCodesCount {
+		recoveryCode, err := makeRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		if err = queries.CreateRecoveryCode(
+			ctx,
+			database.CreateRecoveryCodeParams{
+				UserID:   userId,
+				CodeHash: auth.HashToken(normaliseRecoveryCode(recoveryCode)),
+			},
+		); err != nil {
+			return nil, err
+		}
+		recoveryCodes = append(recoveryCodes, recoveryCode)
+	}
+	return recoveryCodes, nil
+}
+
+func makeRecoveryCode() (string, error) {
+	random := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return empty, err
+	}
+	code := recoveryCodeEncoding.EncodeToString(random)
+	var groups []string
+	for i := 0; i < len(code); i += recoveryCodeGroup {
+		groups = append(groups, code[i:min(i+recoveryCodeGroup, len(code))])
+	}
+	return strings.Join(groups, "-"), nil
+}
+
+func normaliseRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	pngImage "image/png"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestMakeRecoveryCode(t *testing.T) {
+	regexCode := regexp.MustCompile(`^[a-z2-7]{4}(-[a-z2-7]{1,4})+$`)
+	seen := map[string]bool{}
+	for range recoveryCodesCount {
+		code, err := makeRecoveryCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexCode.MatchString(code) {
+			t.Errorf("makeRecoveryCode() = %q, want dash separated groups", code)
+		}
+		if seen[code] {
+			t.Errorf("makeRecoveryCode() repeated %q", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestNormaliseRecoveryCode(t *testing.T) {
+	tests := map[string]string{
+		"abcd-efgh-ijkl-mnop": "abcdefghijklmnop",
+		" ABCD EFGH-ijkl ":    "abcdefghijkl",
+		"abcdefgh":            "abcdefgh",
+	}
+	for input, want := range tests {
+		if output := normaliseRecoveryCode(input); output != want {
+			t.Errorf("normaliseRecoveryCode(%q) = %q, want %q", input, output, want)
+		}
+	}
+}
+
+func TestStartTotpEnrolment(t *testing.T) {
+	db, testDb := newTestDb(map[string]testResult{})
+	config := ApiConfig{DBQueries: database.New(db), EncryptionKey: make([]byte, 32)}
+	enrolment, err := config.startTotpEnrolment(context.Background(), database.User{Email: "walt@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	png, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(enrolment.QrCode, dataUriPng))
+	if err != nil || !strings.HasPrefix(enrolment.QrCode, dataUriPng) {
+		t.Fatalf("startTotpEnrolment() qr code = (%.40s), want a png data uri", enrolment.QrCode)
+	}
+	image, err := pngImage.Decode(bytes.NewReader(png))
+	if err != nil || image.Bounds().Dx() != qrCodeSize || image.Bounds().Dy() != qrCodeSize {
+		t.Errorf("startTotpEnrolment() qr code = (%v), want a %dpx png", err, qrCodeSize)
+	}
+	if !strings.HasPrefix(enrolment.OtpauthUri, "otpauth://totp/") ||
+		!slices.Equal(testDb.ran(), []string{"UpdateUserTotpSecret"}) {
+		t.Errorf("startTotpEnrolment() = (%s) after (%v)", enrolment.OtpauthUri, testDb.ran())
+	}
+}
@@ -1,6 +1,10 @@
 package web
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/mamatb/Chirpy/auth"
+)
 
 func (c *ApiConfig) middleMetricsInc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -15,3 +19,30 @@ func (c *ApiConfig) middleMetricsReset(next http.HandlerFunc) http.HandlerFunc {
 		next.ServeHTTP(w, r)
 	}
 }
+
+// IsRole tells whether role is one that can be given to users.
+func IsRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+func (c *ApiConfig) middleRole(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		var token string
+		var claims auth.Claims
+		if token, err = auth.GetBearerToken(r.Header); err != nil {
+			respPlainUnauthorized(w, r)
+			return
+		}
+		if claims, err = c.Keys.ValidateJWTClaims(token); err != nil {
+			respPlainUnauthorized(w, r)
+			return
+		}
+		if len(claims.ClientId) > 0 || roleRanks[claims.Role] < roleRanks[role] {
+			respPlainForbidden(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+)
+
+func TestAdminHandlersRequireRole(t *testing.T) {
+	config := ApiConfig{Keys: auth.NewKeySet(auth.AlgorithmHs256, "secret", true)}
+	token, err := auth.MakeJWT(uuid.New(), roleUser, "secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]http.HandlerFunc{
+		"GET /admin/profanities":           HandlerGetAdminProfanities(&config),
+		"POST /admin/profanities":          HandlerPostAdminProfanities(&config),
+		"DELETE /admin/profanities/kitten": HandlerDeleteAdminProfanitiesWord(&config),
+		"GET /admin/reports":               HandlerGetAdminReports(&config),
+		"POST /admin/reports/{id}":         HandlerPostAdminReportsId(&config),
+		"GET /admin/decisions":             HandlerGetAdminDecisions(&config),
+	}
+	for input, handler := range tests {
+		for authorization, want := range map[string]int{
+			empty:                               http.StatusUnauthorized,
+			"Bearer invalid":                    http.StatusUnauthorized,
+			authorizationBearer + space + token: http.StatusForbidden,
+		} {
+			request := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+			request.Header.Set(headerAuthorization, authorization)
+			recorder := httptest.NewRecorder()
+			handler(recorder, request)
+			if output := recorder.Code; output != want {
+				t.Errorf(
+					"%s with \"%s\" = (%d), want (%d)",
+					input, authorization, output, want,
+				)
+			}
+		}
+	}
+}
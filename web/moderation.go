@@ -0,0 +1,51 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mamatb/Chirpy/database"
+)
+
+func (c *ApiConfig) moderate(ctx context.Context, report database.Report, chirp database.Chirp,
+	params database.CreateModerationDecisionParams) (database.ModerationDecision, error) {
+	var err error
+	var tx *sql.Tx
+	var decision database.ModerationDecision
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return decision, err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if decision, err = queries.CreateModerationDecision(ctx, params); err != nil {
+		return decision, err
+	}
+	switch params.Action {
+	case moderationActionDismiss:
+		if chirp.Status == chirpStatusModeration {
+			err = queries.UpdateChirpStatus(ctx, database.UpdateChirpStatusParams{
+				ID:     chirp.ID,
+				Status: chirpStatusPublished,
+			})
+		}
+	case moderationActionHide:
+		err = queries.UpdateChirpStatus(ctx, database.UpdateChirpStatusParams{
+			ID:     chirp.ID,
+			Status: chirpStatusHidden,
+		})
+	case moderationActionSuspend:
+		if err = queries.UpdateChirpStatus(ctx, database.UpdateChirpStatusParams{
+			ID:     chirp.ID,
+			Status: chirpStatusHidden,
+		}); err == nil {
+			err = suspendUser(ctx, queries, chirp.UserID.UUID)
+		}
+	}
+	if err != nil {
+		return decision, err
+	}
+	if err = queries.ResolveReport(ctx, report.ID); err != nil {
+		return decision, err
+	}
+	return decision, tx.Commit()
+}
@@ -0,0 +1,426 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+//go:embed templates/oauth_authorize.html
+var oauthAuthorizeHtml string
+
+var (
+	errOauthClientNotFound = errors.New(errorOauthClientNotFound)
+	errOauthGrantType      = oauthError{oauthErrorGrantType, errorOauthGrantType}
+	errOauthInvalidClient  = oauthError{oauthErrorInvalidClient, errorOauthClientNotFound}
+	errOauthInvalidRequest = oauthError{oauthErrorInvalidRequest, errorSomethingWentWrong}
+	oauthAuthorizeTemplate = template.Must(template.New("oauth_authorize").Parse(oauthAuthorizeHtml))
+)
+
+// oauthError carries one of the error codes of RFC 6749, which clients act
+// on, along with a description meant for their developers.
+type oauthError struct {
+	Code        string
+	Description string
+}
+
+func (e oauthError) Error() string {
+	return e.Description
+}
+
+// clientError is a client registration the user can fix, as opposed to a
+// failure on our side.
+type clientError string
+
+func (e clientError) Error() string {
+	return string(e)
+}
+
+// oauthAuthorization is an authorization request whose client and redirect
+// uri were checked, so errors from here on go back to the client.
+type oauthAuthorization struct {
+	Client        database.OauthClient
+	RedirectUri   string
+	Scopes        string
+	State         string
+	CodeChallenge string
+}
+
+// oauthAuthorizePage fills the consent screen, which posts the request back
+// in hidden fields along with the user's credentials.
+type oauthAuthorizePage struct {
+	ClientId      uuid.UUID
+	ClientName    string
+	RedirectUri   string
+	Scope         string
+	State         string
+	CodeChallenge string
+	Scopes        []string
+	Email         string
+	Error         string
+}
+
+func (c *ApiConfig) createOauthClient(ctx context.Context, userId uuid.UUID, name string,
+	redirectUris []string, confidential bool) (database.OauthClient, string, error) {
+	var err error
+	var secret string
+	var client database.OauthClient
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || countGraphemes(name) > oauthClientNameLength {
+		return client, empty, clientError(errorInvalidClientName)
+	}
+	if len(redirectUris) == 0 || len(redirectUris) > oauthMaxRedirectUris {
+		return client, empty, clientError(errorInvalidRedirectUri)
+	}
+	for _, redirectUri := range redirectUris {
+		if !isValidRedirectUri(redirectUri) {
+			return client, empty, clientError(errorInvalidRedirectUri)
+		}
+	}
+	secretHash := sql.NullString{}
+	if confidential {
+		if secret, err = auth.MakeToken(); err != nil {
+			return client, empty, err
+		}
+		secretHash = sql.NullString{String: auth.HashToken(secret), Valid: true}
+	}
+	if client, err = c.DBQueries.CreateOauthClient(
+		ctx,
+		database.CreateOauthClientParams{
+			UserID:       userId,
+			Name:         name,
+			SecretHash:   secretHash,
+			RedirectUris: strings.Join(slices.Compact(redirectUris), space),
+		},
+	); err != nil {
+		return client, empty, err
+	}
+	return client, secret, nil
+}
+
+// deleteOauthClient also ends every session the client holds, which the
+// foreign key alone would only do once the client row is gone for good.
+func (c *ApiConfig) deleteOauthClient(ctx context.Context, userId uuid.UUID, clientId uuid.UUID) error {
+	var err error
+	var tx *sql.Tx
+	var rows int64
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if rows, err = queries.DeleteOauthClient(
+		ctx,
+		database.DeleteOauthClientParams{
+			ID:     clientId,
+			UserID: userId,
+		},
+	); err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errOauthClientNotFound
+	}
+	if err = queries.DeleteRefreshTokensFromClient(ctx, uuid.NullUUID{UUID: clientId, Valid: true}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// parseOauthAuthorization checks an authorization request. It returns false
+// while the redirect uri cannot be trusted yet, in which case the error is
+// shown to the user instead of being sent to the client.
+func (c *ApiConfig) parseOauthAuthorization(ctx context.Context,
+	values url.Values) (oauthAuthorization, bool, error) {
+	var err error
+	var clientId uuid.UUID
+	var authorization oauthAuthorization
+	if clientId, err = uuid.Parse(values.Get(oauthParamClientId)); err != nil {
+		return authorization, false, oauthError{oauthErrorInvalidRequest, errorOauthClientNotFound}
+	}
+	if authorization.Client, err = c.DBQueries.GetOauthClient(ctx, clientId); err != nil {
+		return authorization, false, oauthError{oauthErrorInvalidRequest, errorOauthClientNotFound}
+	}
+	redirectUris := strings.Fields(authorization.Client.RedirectUris)
+	authorization.RedirectUri = values.Get(oauthParamRedirectUri)
+	if len(authorization.RedirectUri) == 0 && len(redirectUris) == 1 {
+		authorization.RedirectUri = redirectUris[0]
+	}
+	if !slices.Contains(redirectUris, authorization.RedirectUri) {
+		return authorization, false, oauthError{oauthErrorInvalidRequest, errorInvalidRedirectUri}
+	}
+	authorization.State = values.Get(oauthParamState)
+	if values.Get(oauthParamResponseType) != oauthResponseTypeCode {
+		return authorization, true, oauthError{oauthErrorResponseType, errorOauthResponseType}
+	}
+	if authorization.Scopes, err = normaliseScopes(strings.Fields(values.Get(oauthParamScope))); err != nil {
+		return authorization, true, oauthError{oauthErrorInvalidScope, errorInvalidScopes}
+	}
+	authorization.CodeChallenge = values.Get(oauthParamCodeChallenge)
+	if values.Get(oauthParamChallengeMethod) != oauthChallengeS256 ||
+		!regexCodeChallenge.MatchString(authorization.CodeChallenge) {
+		return authorization, true, oauthError{oauthErrorInvalidRequest, errorOauthCodeChallenge}
+	}
+	return authorization, true, nil
+}
+
+// authorizeOauthClient hands the client a single use code for what the user
+// agreed to, which is only worth anything together with the PKCE verifier.
+func (c *ApiConfig) authorizeOauthClient(ctx context.Context, authorization oauthAuthorization,
+	userId uuid.UUID) (string, error) {
+	var err error
+	var code string
+	if code, err = auth.MakeToken(); err != nil {
+		return empty, err
+	}
+	if _, err = c.DBQueries.CreateOauthAuthorizationCode(
+		ctx,
+		database.CreateOauthAuthorizationCodeParams{
+			ClientID:      authorization.Client.ID,
+			UserID:        userId,
+			CodeHash:      auth.HashToken(code),
+			RedirectUri:   authorization.RedirectUri,
+			Scopes:        authorization.Scopes,
+			CodeChallenge: authorization.CodeChallenge,
+			ExpiresAt:     time.Now().Add(oauthCodeExpiry),
+		},
+	); err != nil {
+		return empty, err
+	}
+	return code, nil
+}
+
+// authenticateOauthClient accepts the client credentials through HTTP Basic
+// or the request body. Public clients have no secret and prove themselves
+// with PKCE instead.
+func (c *ApiConfig) authenticateOauthClient(ctx context.Context, r *http.Request) (database.OauthClient, error) {
+	var err error
+	var clientId uuid.UUID
+	var client database.OauthClient
+	id, secret, ok := r.BasicAuth()
+	if ok {
+		if id, err = url.QueryUnescape(id); err != nil {
+			return client, err
+		}
+		if secret, err = url.QueryUnescape(secret); err != nil {
+			return client, err
+		}
+	} else {
+		id, secret = r.PostForm.Get(oauthParamClientId), r.PostForm.Get(oauthParamClientSecret)
+	}
+	if clientId, err = uuid.Parse(id); err != nil {
+		return client, errOauthClientNotFound
+	}
+	if client, err = c.DBQueries.GetOauthClient(ctx, clientId); err != nil {
+		return client, errOauthClientNotFound
+	}
+	if !client.SecretHash.Valid {
+		if len(secret) > 0 {
+			return client, errOauthClientNotFound
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(secret)), []byte(client.SecretHash.String)) != 1 {
+		return client, errOauthClientNotFound
+	}
+	return client, nil
+}
+
+// exchangeAuthorizationCode spends the code before checking it, so a code
+// sent along with the wrong verifier cannot be tried again.
+func (c *ApiConfig) exchangeAuthorizationCode(ctx context.Context, oauthClient database.OauthClient,
+	code string, redirectUri string, verifier string,
+	client sessionClient) (database.User, database.RefreshToken, string, error) {
+	var err error
+	var refreshToken string
+	var user database.User
+	var session database.RefreshToken
+	var authorization database.OauthAuthorizationCode
+	invalidGrant := oauthError{oauthErrorInvalidGrant, errorOauthInvalidCode}
+	if authorization, err = c.DBQueries.UpdateOauthAuthorizationCodeUsed(ctx, auth.HashToken(code)); err != nil {
+		return user, session, empty, invalidGrant
+	}
+	if authorization.ClientID != oauthClient.ID || authorization.RedirectUri != redirectUri ||
+		!verifyCodeChallenge(verifier, authorization.CodeChallenge) {
+		return user, session, empty, invalidGrant
+	}
+	if user, err = c.DBQueries.GetUserFromId(ctx, authorization.UserID); err != nil {
+		return user, session, empty, invalidGrant
+	}
+	if !isUserActive(user) {
+		return user, session, empty, invalidGrant
+	}
+	if refreshToken, session, err = createRefreshToken(
+		ctx,
+		c.DBQueries,
+		user.ID,
+		client,
+		oauthGrant{
+			ClientId: uuid.NullUUID{UUID: oauthClient.ID, Valid: true},
+			Scopes:   authorization.Scopes,
+		},
+	); err != nil {
+		return user, session, empty, err
+	}
+	return user, session, refreshToken, nil
+}
+
+// refreshOauthToken rotates a refresh token the client holds, keeping the
+// scopes it was granted.
+func (c *ApiConfig) refreshOauthToken(ctx context.Context, oauthClient database.OauthClient,
+	refreshToken string, client sessionClient) (database.User, database.RefreshToken, string, error) {
+	var err error
+	var user database.User
+	var session database.RefreshToken
+	invalidGrant := oauthError{oauthErrorInvalidGrant, errorInvalidRefreshToken}
+	if session, refreshToken, err = c.rotateRefreshToken(
+		ctx,
+		refreshToken,
+		client,
+		uuid.NullUUID{UUID: oauthClient.ID, Valid: true},
+	); errors.Is(err, errRefreshTokenReused) {
+		return user, session, empty, oauthError{oauthErrorInvalidGrant, errorRefreshTokenReused}
+	} else if err != nil {
+		return user, session, empty, invalidGrant
+	}
+	if user, err = c.DBQueries.GetUserFromId(ctx, session.UserID.UUID); err != nil {
+		return user, session, empty, invalidGrant
+	}
+	if !isUserActive(user) {
+		return user, session, empty, invalidGrant
+	}
+	return user, session, refreshToken, nil
+}
+
+// revokeOauthToken ends the session behind a refresh token issued to the
+// client. Access tokens expire on their own within the hour.
+func (c *ApiConfig) revokeOauthToken(ctx context.Context, oauthClient database.OauthClient, token string) error {
+	session, err := c.DBQueries.GetRefreshTokenFromHash(ctx, auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if session.ClientID.UUID != oauthClient.ID || !session.ClientID.Valid {
+		return nil
+	}
+	return c.DBQueries.DeleteRefreshTokensFromFamily(ctx, session.FamilyID)
+}
+
+// introspectOauthToken only reports on tokens issued to the asking client,
+// every other token looks inactive to it.
+func (c *ApiConfig) introspectOauthToken(ctx context.Context, oauthClient database.OauthClient,
+	token string) jsonOauthIntrospection {
+	inactive := jsonOauthIntrospection{Active: false}
+	if session, err := c.DBQueries.GetRefreshTokenFromHash(ctx, auth.HashToken(token)); err == nil {
+		if session.ClientID.UUID != oauthClient.ID || !session.ClientID.Valid || !session.UserID.Valid ||
+			session.RevokedAt.Valid || session.RotatedAt.Valid || !time.Now().Before(session.ExpiresAt) {
+			return inactive
+		}
+		return jsonOauthIntrospection{
+			Active:    true,
+			Scope:     session.Scopes,
+			ClientId:  oauthClient.ID.String(),
+			Subject:   session.UserID.UUID.String(),
+			TokenType: oauthTokenTypeRefresh,
+			ExpiresAt: session.ExpiresAt.Unix(),
+			IssuedAt:  session.CreatedAt.Unix(),
+		}
+	}
+	claims, err := c.Keys.ValidateJWTClaims(token)
+	if err != nil || claims.ClientId != oauthClient.ID.String() {
+		return inactive
+	}
+	return jsonOauthIntrospection{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientId:  claims.ClientId,
+		Subject:   claims.Subject,
+		TokenType: oauthTokenTypeAccess,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		Issuer:    claims.Issuer,
+	}
+}
+
+func newOauthAuthorizePage(authorization oauthAuthorization, email string, message string) oauthAuthorizePage {
+	page := oauthAuthorizePage{
+		ClientId:      authorization.Client.ID,
+		ClientName:    authorization.Client.Name,
+		RedirectUri:   authorization.RedirectUri,
+		Scope:         authorization.Scopes,
+		State:         authorization.State,
+		CodeChallenge: authorization.CodeChallenge,
+		Email:         email,
+		Error:         message,
+	}
+	for _, scope := range strings.Fields(authorization.Scopes) {
+		page.Scopes = append(page.Scopes, oauthScopeDescriptions[scope])
+	}
+	return page
+}
+
+// oauthRedirect appends parameters to a registered redirect uri, keeping
+// any query it already has.
+func oauthRedirect(redirectUri string, params url.Values) string {
+	redirect, err := url.Parse(redirectUri)
+	if err != nil {
+		return redirectUri
+	}
+	query := redirect.Query()
+	for key, values := range params {
+		for _, value := range values {
+			if len(value) > 0 {
+				query.Add(key, value)
+			}
+		}
+	}
+	redirect.RawQuery = query.Encode()
+	return redirect.String()
+}
+
+// isValidRedirectUri allows https, and plain http only back to the machine
+// the client runs on, as native apps do.
+func isValidRedirectUri(redirectUri string) bool {
+	redirect, err := url.Parse(redirectUri)
+	if err != nil || !redirect.IsAbs() || len(redirect.Host) == 0 || redirect.User != nil ||
+		len(redirect.Fragment) > 0 || strings.Contains(redirectUri, "#") {
+		return false
+	}
+	switch redirect.Scheme {
+	case "https":
+		return true
+	case "http":
+		host := redirect.Hostname()
+		ip := net.ParseIP(host)
+		return host == "localhost" || (ip != nil && ip.IsLoopback())
+	default:
+		return false
+	}
+}
+
+// verifyCodeChallenge checks a PKCE verifier against its S256 challenge.
+func verifyCodeChallenge(verifier string, challenge string) bool {
+	if !regexCodeVerifier.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+	if !regexCodeChallenge.MatchString(challenge) {
+		t.Errorf("regexCodeChallenge rejects %q", challenge)
+	}
+	if !verifyCodeChallenge(verifier, challenge) {
+		t.Errorf("verifyCodeChallenge(%q, %q) = false, want true", verifier, challenge)
+	}
+	if verifyCodeChallenge(verifier[1:]+"a", challenge) {
+		t.Errorf("verifyCodeChallenge() accepts the wrong verifier")
+	}
+	if verifyCodeChallenge("short", challenge) {
+		t.Errorf("verifyCodeChallenge() accepts a verifier shorter than 43 characters")
+	}
+}
+
+func TestIsValidRedirectUri(t *testing.T) {
+	tests := map[string]bool{
+		"https://app.example/callback":       true,
+		"https://app.example/callback?a=b":   true,
+		"http://localhost:8000/callback":     true,
+		"http://127.0.0.1:8000/callback":     true,
+		"http://[::1]/callback":              true,
+		"http://app.example/callback":        false,
+		"https://app.example/callback#token": false,
+		"https://user@app.example/callback":  false,
+		"javascript:alert(1)":                false,
+		"/callback":                          false,
+		"com.example.app:/callback":          false,
+	}
+	for input, want := range tests {
+		if output := isValidRedirectUri(input); output != want {
+			t.Errorf("isValidRedirectUri(%q) = %t, want %t", input, output, want)
+		}
+	}
+}
+
+func TestOauthRedirect(t *testing.T) {
+	redirect, err := url.Parse(oauthRedirect("https://app.example/callback?app=1", url.Values{
+		oauthParamCode:  {"abc"},
+		oauthParamState: {empty},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := redirect.Query()
+	if query.Get("app") != "1" || query.Get(oauthParamCode) != "abc" || query.Has(oauthParamState) {
+		t.Errorf("oauthRedirect() = %q", redirect)
+	}
+}
@@ -0,0 +1,105 @@
+package web
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+var errOidcNoAccount = errors.New(errorOidcNoAccount)
+
+// startOidcLogin remembers the state, nonce and PKCE verifier of a login
+// with the identity provider, for the client that asked for it, and returns
+// where to send the user.
+func (c *ApiConfig) startOidcLogin(ctx context.Context, fingerprint string) (string, error) {
+	var err error
+	var state, nonce, verifier string
+	if state, err = auth.MakeToken(); err != nil {
+		return empty, err
+	}
+	if nonce, err = auth.MakeToken(); err != nil {
+		return empty, err
+	}
+	if verifier, err = auth.MakeToken(); err != nil {
+		return empty, err
+	}
+	if err = c.DBQueries.CreateOidcLogin(
+		ctx,
+		database.CreateOidcLoginParams{
+			StateHash:    auth.HashToken(state),
+			Nonce:        nonce,
+			CodeVerifier: verifier,
+			Fingerprint:  fingerprint,
+			ExpiresAt:    time.Now().Add(oidcLoginExpiry),
+		},
+	); err != nil {
+		return empty, err
+	}
+	return c.Oidc.AuthCodeUrl(ctx, state, nonce, verifier)
+}
+
+// finishOidcLogin spends the state before anything else, like magic links,
+// then finds the user the provider vouched for.
+func (c *ApiConfig) finishOidcLogin(ctx context.Context, state string, code string,
+	fingerprint string) (database.User, error) {
+	var err error
+	var login database.OidcLogin
+	var claims auth.OidcClaims
+	if login, err = c.DBQueries.UpdateOidcLoginUsed(ctx, auth.HashToken(state)); err != nil {
+		return database.User{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(login.Fingerprint), []byte(fingerprint)) != 1 {
+		return database.User{}, errors.New(errorInvalidToken)
+	}
+	if claims, err = c.Oidc.Exchange(ctx, code, login.CodeVerifier, login.Nonce); err != nil {
+		return database.User{}, err
+	}
+	return c.linkOidcIdentity(ctx, claims)
+}
+
+// linkOidcIdentity returns the user already linked to the identity, or else
+// links the user with the same email. Both sides must have verified it, so
+// nobody can claim an account by signing up with someone else's address.
+func (c *ApiConfig) linkOidcIdentity(ctx context.Context, claims auth.OidcClaims) (database.User, error) {
+	var err error
+	var identity database.UserIdentity
+	var user database.User
+	if identity, err = c.DBQueries.GetUserIdentity(
+		ctx,
+		database.GetUserIdentityParams{
+			Issuer:  claims.Issuer,
+			Subject: claims.Subject,
+		},
+	); err == nil {
+		return c.DBQueries.GetUserFromId(ctx, identity.UserID)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return user, err
+	}
+	if !claims.EmailVerified || len(claims.Email) == 0 {
+		return user, errOidcNoAccount
+	}
+	if user, err = c.DBQueries.GetUser(ctx, claims.Email); errors.Is(err, sql.ErrNoRows) {
+		return user, errOidcNoAccount
+	} else if err != nil {
+		return user, err
+	}
+	if !user.EmailVerifiedAt.Valid {
+		return user, errOidcNoAccount
+	}
+	if err = c.DBQueries.CreateUserIdentity(
+		ctx,
+		database.CreateUserIdentityParams{
+			UserID:  user.ID,
+			Issuer:  claims.Issuer,
+			Subject: claims.Subject,
+		},
+	); err != nil {
+		return user, err
+	}
+	return user, nil
+}
@@ -0,0 +1,104 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+// sendPasswordReset runs outside the request so that unknown addresses and
+// rate limited ones answer exactly like real ones
+func (c *ApiConfig) sendPasswordReset(ctx context.Context, email string) error {
+	var err error
+	var token string
+	var user database.User
+	var recent int64
+	if user, err = c.DBQueries.GetUser(ctx, email); err != nil {
+		return nil
+	}
+	if recent, err = c.DBQueries.CountPasswordResetsFromUser(
+		ctx,
+		database.CountPasswordResetsFromUserParams{
+			UserID:    user.ID,
+			CreatedAt: time.Now().Add(-passwordResetWindow),
+		},
+	); err != nil || recent >= passwordResetLimit {
+		return err
+	}
+	if token, err = auth.MakeToken(); err != nil {
+		return err
+	}
+	if _, err = c.DBQueries.CreatePasswordReset(
+		ctx,
+		database.CreatePasswordResetParams{
+			UserID:    user.ID,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(passwordResetExpiry),
+		},
+	); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set(queryToken, token)
+	return c.Mailer.Send(ctx, Mail{
+		To:      user.Email,
+		Subject: mailPasswordResetSubject,
+		Body:    fmt.Sprintf(mailPasswordResetBody, c.BaseUrl+passwordResetPath+"?"+query.Encode()),
+	})
+}
+
+func (c *ApiConfig) sendPasswordResetAsync(email string) {
+	go func() {
+		if err := c.sendPasswordReset(context.Background(), email); err != nil {
+			log.Print(err)
+		}
+	}()
+}
+
+func (c *ApiConfig) resetPassword(ctx context.Context, token string, hash string) error {
+	var err error
+	var tx *sql.Tx
+	var reset database.PasswordReset
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	// spent in the same statement that checks it, so that concurrent
+	// requests cannot both redeem one token
+	if reset, err = queries.UpdatePasswordResetUsed(ctx, auth.HashToken(token)); err != nil {
+		return err
+	}
+	if err = queries.UpdatePasswordResetsUsedFromUser(ctx, reset.UserID); err != nil {
+		return err
+	}
+	if err = queries.UpdateUserPassword(
+		ctx,
+		database.UpdateUserPasswordParams{
+			ID:             reset.UserID,
+			HashedPassword: hash,
+		},
+	); err != nil {
+		return err
+	}
+	if err = queries.DeleteRefreshTokensFromUser(
+		ctx,
+		uuid.NullUUID{UUID: reset.UserID, Valid: true},
+	); err != nil {
+		return err
+	}
+	if err = queries.DeletePersonalAccessTokensFromUser(ctx, reset.UserID); err != nil {
+		return err
+	}
+	if _, err = queries.UpdateUserTokenVersion(ctx, reset.UserID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
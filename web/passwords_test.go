@@ -0,0 +1,41 @@
+package web
+
+import (
+	"context"
+	"database/sql/driver"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestResetPassword(t *testing.T) {
+	now := time.Now()
+	reset := testResult{
+		columns: []string{"id", "created_at", "user_id", "token_hash", "expires_at", "used_at"},
+		rows:    [][]driver.Value{{uuid.NewString(), now, uuid.NewString(), "hash", now, now}},
+	}
+	tests := map[string][]string{
+		"unspent": {"UpdatePasswordResetUsed", "UpdatePasswordResetsUsedFromUser", "UpdateUserPassword",
+			"DeleteRefreshTokensFromUser", "DeletePersonalAccessTokensFromUser", "UpdateUserTokenVersion"},
+		"spent": {"UpdatePasswordResetUsed"},
+	}
+	for input, want := range tests {
+		results := map[string]testResult{"UpdateUserTokenVersion": testUserResult(uuid.New())}
+		if input == "unspent" {
+			results["UpdatePasswordResetUsed"] = reset
+		}
+		db, testDb := newTestDb(results)
+		config := ApiConfig{DB: db, DBQueries: database.New(db)}
+		err := config.resetPassword(context.Background(), "token", "hash")
+		if output := testDb.ran(); !slices.Equal(output, want) || (err == nil) != (input == "unspent") ||
+			testDb.committed != (input == "unspent") {
+			t.Errorf(
+				"resetPassword() with an %s token = (%v, committed %t) after (%v), want after (%v)",
+				input, err, testDb.committed, output, want,
+			)
+		}
+	}
+}
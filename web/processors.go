@@ -0,0 +1,396 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+type ChirpStage int
+
+const (
+	ChirpStageValidate ChirpStage = iota
+	ChirpStageNormalise
+	ChirpStageFilter
+	ChirpStageEnrich
+	ChirpStageReject
+)
+
+type ChirpDraft struct {
+	User        database.User
+	Body        string
+	Links       []string
+	Status      string
+	Fingerprint string
+	SpamScore   float64
+	batch       *chirpBatch
+}
+
+// chirpBatch tracks the chirps stored earlier in the same import. They keep
+// the times they were created at and are not committed yet, so the queries
+// looking for duplicates and bursts of recent chirps cannot see them.
+type chirpBatch struct {
+	fingerprints map[string]bool
+	count        int64
+}
+
+func newChirpBatch() *chirpBatch {
+	return &chirpBatch{fingerprints: map[string]bool{}}
+}
+
+func (b *chirpBatch) add(draft ChirpDraft) {
+	b.fingerprints[draft.Fingerprint] = true
+	b.count++
+}
+
+type ChirpProcessor interface {
+	Name() string
+	Stage() ChirpStage
+	Process(ctx context.Context, draft *ChirpDraft) error
+}
+
+type ChirpRejection struct {
+	Processor string         `json:"processor"`
+	Reason    string         `json:"reason"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+func (r ChirpRejection) Error() string {
+	return r.Message
+}
+
+type ChirpRejections []ChirpRejection
+
+func (r ChirpRejections) Error() string {
+	return r[0].Message
+}
+
+func (c *ApiConfig) RegisterChirpProcessors(processors ...ChirpProcessor) {
+	c.chirpProcessors = append(c.chirpProcessors, processors...)
+	slices.SortStableFunc(c.chirpProcessors, func(a, b ChirpProcessor) int {
+		return int(a.Stage()) - int(b.Stage())
+	})
+}
+
+func (c *ApiConfig) processChirp(ctx context.Context, draft *ChirpDraft) error {
+	return c.processChirpStages(ctx, draft, ChirpStageReject)
+}
+
+func (c *ApiConfig) processChirpStages(ctx context.Context, draft *ChirpDraft,
+	lastStage ChirpStage) error {
+	var rejections ChirpRejections
+	for processorIdx, processor := range c.chirpProcessors {
+		var rejection ChirpRejection
+		if processor.Stage() > lastStage {
+			break
+		}
+		if err := processor.Process(ctx, draft); errors.As(err, &rejection) {
+			if len(rejection.Processor) == 0 {
+				rejection.Processor = processor.Name()
+			}
+			rejections = append(rejections, rejection)
+		} else if err != nil {
+			return err
+		}
+		lastInStage := processorIdx == len(c.chirpProcessors)-1 ||
+			c.chirpProcessors[processorIdx+1].Stage() != processor.Stage()
+		if lastInStage && len(rejections) > 0 {
+			return rejections
+		}
+	}
+	return nil
+}
+
+// createChirp stores a processed draft in a transaction of its own, so a
+// failure halfway leaves nothing behind.
+func (c *ApiConfig) createChirp(ctx context.Context, draft ChirpDraft) (database.Chirp,
+	[]database.ChirpLink, error) {
+	var err error
+	var tx *sql.Tx
+	var chirp database.Chirp
+	var links []database.ChirpLink
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return chirp, nil, err
+	}
+	defer tx.Rollback()
+	if chirp, links, err = insertChirp(ctx, c.DBQueries.WithTx(tx), draft, time.Time{}); err != nil {
+		return chirp, nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return chirp, nil, err
+	}
+	for _, link := range links {
+		c.enqueueLinkPreview(link)
+	}
+	return chirp, links, nil
+}
+
+// insertChirp stores a processed draft along with its links, and the report
+// that holds it for moderation. Imported chirps keep the time they were
+// created at, new ones leave it zero.
+func insertChirp(ctx context.Context, queries *database.Queries, draft ChirpDraft,
+	createdAt time.Time) (database.Chirp, []database.ChirpLink, error) {
+	var err error
+	var chirp database.Chirp
+	var links []database.ChirpLink
+	userId := uuid.NullUUID{UUID: draft.User.ID, Valid: true}
+	if createdAt.IsZero() {
+		chirp, err = queries.CreateChirp(
+			ctx,
+			database.CreateChirpParams{
+				Body:        draft.Body,
+				UserID:      userId,
+				Status:      draft.Status,
+				Fingerprint: draft.Fingerprint,
+				SpamScore:   draft.SpamScore,
+			},
+		)
+	} else {
+		chirp, err = queries.CreateChirpImported(
+			ctx,
+			database.CreateChirpImportedParams{
+				CreatedAt:   createdAt.UTC(),
+				Body:        draft.Body,
+				UserID:      userId,
+				Status:      draft.Status,
+				Fingerprint: draft.Fingerprint,
+				SpamScore:   draft.SpamScore,
+			},
+		)
+	}
+	if err != nil {
+		return chirp, nil, err
+	}
+	if links, err = createChirpLinks(ctx, queries, chirp, draft.Links); err != nil {
+		return chirp, nil, err
+	}
+	if chirp.Status == chirpStatusModeration {
+		if _, err = queries.CreateReport(
+			ctx,
+			database.CreateReportParams{
+				ChirpID: chirp.ID,
+				Reason:  reportReasonSpam,
+				Details: fmt.Sprintf(reportDetailsSpam, chirp.SpamScore),
+			},
+		); err != nil {
+			return chirp, nil, err
+		}
+	}
+	return chirp, links, nil
+}
+
+func DefaultChirpProcessors(config *ApiConfig) []ChirpProcessor {
+	return []ChirpProcessor{
+		chirpLengthProcessor{config: config},
+		chirpNormaliseProcessor{},
+		chirpProfanityProcessor{config: config},
+		chirpLinksProcessor{},
+		chirpDuplicateProcessor{config: config},
+		chirpSpamProcessor{config: config},
+	}
+}
+
+type chirpLengthProcessor struct {
+	config *ApiConfig
+}
+
+func (p chirpLengthProcessor) Name() string {
+	return processorLength
+}
+
+func (p chirpLengthProcessor) Stage() ChirpStage {
+	return ChirpStageValidate
+}
+
+func (p chirpLengthProcessor) Process(_ context.Context, draft *ChirpDraft) error {
+	maxLength := p.config.ChirpMaxLength
+	if draft.User.IsChirpyRed {
+		maxLength = p.config.ChirpMaxLengthRed
+	}
+	if remaining := maxLength - chirpLength(draft.Body); remaining < 0 {
+		return ChirpRejection{
+			Reason:  reasonTooLong,
+			Message: errorChirpTooLong,
+			Details: map[string]any{"remaining": remaining},
+		}
+	}
+	return nil
+}
+
+type chirpNormaliseProcessor struct{}
+
+func (p chirpNormaliseProcessor) Name() string {
+	return processorNormalise
+}
+
+func (p chirpNormaliseProcessor) Stage() ChirpStage {
+	return ChirpStageNormalise
+}
+
+func (p chirpNormaliseProcessor) Process(_ context.Context, draft *ChirpDraft) error {
+	draft.Body = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, strings.ReplaceAll(draft.Body, "\r\n", "\n")))
+	if len(draft.Body) == 0 {
+		return ChirpRejection{
+			Reason:  reasonEmpty,
+			Message: errorChirpEmpty,
+		}
+	}
+	return nil
+}
+
+type chirpProfanityProcessor struct {
+	config *ApiConfig
+}
+
+func (p chirpProfanityProcessor) Name() string {
+	return processorProfanity
+}
+
+func (p chirpProfanityProcessor) Stage() ChirpStage {
+	return ChirpStageFilter
+}
+
+func (p chirpProfanityProcessor) Process(_ context.Context, draft *ChirpDraft) error {
+	draft.Body = p.config.profanities.clean(draft.Body)
+	return nil
+}
+
+type chirpLinksProcessor struct{}
+
+func (p chirpLinksProcessor) Name() string {
+	return processorLinks
+}
+
+func (p chirpLinksProcessor) Stage() ChirpStage {
+	return ChirpStageEnrich
+}
+
+func (p chirpLinksProcessor) Process(_ context.Context, draft *ChirpDraft) error {
+	draft.Links = findUrlStrings(draft.Body)
+	return nil
+}
+
+type chirpDuplicateProcessor struct {
+	config *ApiConfig
+}
+
+func (p chirpDuplicateProcessor) Name() string {
+	return processorDuplicate
+}
+
+func (p chirpDuplicateProcessor) Stage() ChirpStage {
+	return ChirpStageReject
+}
+
+func (p chirpDuplicateProcessor) Process(ctx context.Context, draft *ChirpDraft) error {
+	var err error
+	var duplicates int64
+	draft.Fingerprint = chirpFingerprint(draft.Body)
+	if duplicates, err = p.config.DBQueries.CountChirpsDuplicates(
+		ctx,
+		database.CountChirpsDuplicatesParams{
+			UserID:      uuid.NullUUID{UUID: draft.User.ID, Valid: true},
+			Fingerprint: draft.Fingerprint,
+			CreatedAt:   time.Now().Add(-p.config.DuplicateWindow),
+		},
+	); err != nil {
+		return err
+	}
+	if duplicates > 0 || (draft.batch != nil && draft.batch.fingerprints[draft.Fingerprint]) {
+		return ChirpRejection{
+			Reason:  reasonDuplicate,
+			Message: errorChirpDuplicate,
+		}
+	}
+	return nil
+}
+
+type chirpSpamProcessor struct {
+	config *ApiConfig
+}
+
+func (p chirpSpamProcessor) Name() string {
+	return processorSpam
+}
+
+func (p chirpSpamProcessor) Stage() ChirpStage {
+	return ChirpStageReject
+}
+
+func (p chirpSpamProcessor) Process(ctx context.Context, draft *ChirpDraft) error {
+	var err error
+	var recent int64
+	draft.SpamScore = spamScore(draft.Body, len(draft.Links))
+	if time.Since(draft.User.CreatedAt) < p.config.SpamNewAccountAge {
+		if recent, err = p.config.DBQueries.CountChirpsFromUser(
+			ctx,
+			database.CountChirpsFromUserParams{
+				UserID:    uuid.NullUUID{UUID: draft.User.ID, Valid: true},
+				CreatedAt: time.Now().Add(-time.Hour),
+			},
+		); err != nil {
+			return err
+		}
+		if draft.batch != nil {
+			recent += draft.batch.count
+		}
+		if recent >= p.config.SpamNewAccountRate {
+			draft.SpamScore += spamWeightVelocity
+		}
+	}
+	if draft.SpamScore >= p.config.SpamThreshold {
+		draft.Status = chirpStatusModeration
+	}
+	return nil
+}
+
+func chirpFingerprint(body string) string {
+	fingerprint := sha256.Sum256([]byte(normaliseChirp(body)))
+	return hex.EncodeToString(fingerprint[:])
+}
+
+func normaliseChirp(body string) string {
+	return strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, body)), space)
+}
+
+func spamScore(body string, links int) float64 {
+	score := 0.0
+	if words := len(strings.Fields(body)); words > 0 {
+		score += spamWeightLinkDensity * float64(links) / float64(words)
+	}
+	if links > spamMaxLinks {
+		score += spamWeightLink * float64(links-spamMaxLinks)
+	}
+	mentions, seen := regexMention.FindAllString(strings.ToLower(body), -1), map[string]bool{}
+	if len(mentions) > spamMaxMentions {
+		score += spamWeightMention * float64(len(mentions)-spamMaxMentions)
+	}
+	for _, mention := range mentions {
+		if seen[mention] {
+			score += spamWeightMentionRepeated
+		}
+		seen[mention] = true
+	}
+	return score
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testChirpProcessor struct {
+	name   string
+	stage  ChirpStage
+	reject bool
+}
+
+func (p testChirpProcessor) Name() string {
+	return p.name
+}
+
+func (p testChirpProcessor) Stage() ChirpStage {
+	return p.stage
+}
+
+func (p testChirpProcessor) Process(_ context.Context, draft *ChirpDraft) error {
+	draft.Body += p.name
+	if p.reject {
+		return ChirpRejection{Reason: p.name, Message: p.name}
+	}
+	return nil
+}
+
+func TestProcessChirp(t *testing.T) {
+	config := ApiConfig{}
+	config.RegisterChirpProcessors(
+		testChirpProcessor{name: "e", stage: ChirpStageEnrich},
+		testChirpProcessor{name: "v", stage: ChirpStageValidate},
+		testChirpProcessor{name: "f", stage: ChirpStageFilter},
+		testChirpProcessor{name: "n", stage: ChirpStageNormalise},
+	)
+	draft := ChirpDraft{}
+	if err := config.processChirp(context.Background(), &draft); err != nil || draft.Body != "vnfe" {
+		t.Errorf(
+			"processChirp() = (\"%s\", %v), want (\"vnfe\", nil)",
+			draft.Body, err,
+		)
+	}
+
+	config.RegisterChirpProcessors(
+		testChirpProcessor{name: "x", stage: ChirpStageFilter, reject: true},
+		testChirpProcessor{name: "y", stage: ChirpStageFilter, reject: true},
+	)
+	draft = ChirpDraft{}
+	var rejections ChirpRejections
+	if err := config.processChirp(context.Background(), &draft); !errors.As(err, &rejections) ||
+		len(rejections) != 2 || rejections[0].Processor != "x" || draft.Body != "vnfxy" {
+		t.Errorf(
+			"processChirp() = (\"%s\", %v), want (\"vnfxy\", [x y])",
+			draft.Body, err,
+		)
+	}
+}
+
+func TestChirpLengthProcessor(t *testing.T) {
+	config := ApiConfig{ChirpMaxLength: 140, ChirpMaxLengthRed: 280}
+	processor := chirpLengthProcessor{config: &config}
+	tests := map[int]bool{
+		140: false,
+		141: true,
+		280: true,
+	}
+	for input, want := range tests {
+		draft := ChirpDraft{Body: strings.Repeat("🐦", input)}
+		var rejection ChirpRejection
+		if output := errors.As(processor.Process(context.Background(), &draft), &rejection); output != want {
+			t.Errorf(
+				"Process(%d chirps) = (%t), want (%t)",
+				input, output, want,
+			)
+		}
+		draft.User.IsChirpyRed = true
+		if err := processor.Process(context.Background(), &draft); err != nil {
+			t.Errorf(
+				"Process(%d chirps, red) = (%v), want (nil)",
+				input, err,
+			)
+		}
+	}
+}
+
+func TestNormaliseChirp(t *testing.T) {
+	tests := map[string]string{
+		"Hello, World!":        "hello world",
+		"  hello \n\t world  ": "hello world",
+		"HELLO WORLD!!!":       "hello world",
+	}
+	for input, want := range tests {
+		if output := normaliseChirp(input); output != want {
+			t.Errorf(
+				"normaliseChirp(\"%s\") = (\"%s\"), want (\"%s\")",
+				input, output, want,
+			)
+		}
+	}
+}
+
+func TestSpamScore(t *testing.T) {
+	threshold := 1.0
+	testsOk := map[string]int{
+		"just a normal chirp":                         0,
+		"look at this https://example.com":            1,
+		"hey @alice @bob have you seen this?":         0,
+		"https://a.example https://b.example":         2,
+		"thanks @alice @bob @carol @dave for the fun": 0,
+	}
+	testsErr := map[string]int{
+		"https://a.example https://b.example https://c.example": 3,
+		"@alice @alice @alice @alice follow me":                 0,
+		"@a @b @c @d @e @f @g @h @i @j @k @l @m @n @o":          0,
+	}
+	for input, links := range testsOk {
+		if output := spamScore(input, links); output >= threshold {
+			t.Errorf(
+				"spamScore(\"%s\", %d) = (%.2f), want (< %.2f)",
+				input, links, output, threshold,
+			)
+		}
+	}
+	for input, links := range testsErr {
+		if output := spamScore(input, links); output < threshold {
+			t.Errorf(
+				"spamScore(\"%s\", %d) = (%.2f), want (>= %.2f)",
+				input, links, output, threshold,
+			)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/mamatb/Chirpy/database"
+)
+
+var profanityConfusables = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '8': 'b', '@': 'a', '$': 's',
+	'а': 'a', 'в': 'b', 'е': 'e', 'к': 'k', 'м': 'm', 'н': 'h', 'о': 'o', 'р': 'p',
+	'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'ι': 'i', 'κ': 'k', 'ν': 'v', 'ο': 'o', 'ρ': 'p',
+	'τ': 't', 'υ': 'u', 'χ': 'x',
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ç': 'c',
+	'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i',
+	'ñ': 'n', 'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u',
+}
+
+type profanityFilter struct {
+	mutex      sync.RWMutex
+	words      map[string]bool
+	substrings []string
+}
+
+func (c *ApiConfig) LoadProfanities(ctx context.Context) error {
+	var err error
+	var profanities []database.Profanity
+	if profanities, err = c.DBQueries.GetProfanities(ctx); err != nil {
+		return err
+	}
+	words, substrings := map[string]bool{}, []string{}
+	for _, profanity := range profanities {
+		if profanity.Mode == profanityModeSubstring {
+			substrings = append(substrings, profanity.Word)
+		} else {
+			words[profanity.Word] = true
+		}
+	}
+	c.profanities.mutex.Lock()
+	defer c.profanities.mutex.Unlock()
+	c.profanities.words, c.profanities.substrings = words, substrings
+	return nil
+}
+
+func (f *profanityFilter) clean(body string) string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return regexWord.ReplaceAllStringFunc(body, func(token string) string {
+		word := strings.TrimFunc(token, isProfanityPadding)
+		if len(word) == 0 || !f.isProfane(normaliseProfanity(word)) {
+			return token
+		}
+		start := strings.Index(token, word)
+		return token[:start] + profanityReplacement + token[start+len(word):]
+	})
+}
+
+func (f *profanityFilter) isProfane(word string) bool {
+	if f.words[word] {
+		return true
+	}
+	for _, substring := range f.substrings {
+		if strings.Contains(word, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func normaliseProfanity(word string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if r >= 0xff01 && r <= 0xff5e { // fullwidth forms
+			r = unicode.ToLower(r - 0xfee0)
+		}
+		if confusable, ok := profanityConfusables[r]; ok {
+			r = confusable
+		}
+		if unicode.IsLetter(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+func isProfanityPadding(r rune) bool {
+	_, confusable := profanityConfusables[r]
+	return !confusable && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
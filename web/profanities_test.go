@@ -0,0 +1,29 @@
+package web
+
+import "testing"
+
+func TestProfanityFilterClean(t *testing.T) {
+	filter := profanityFilter{
+		words:      map[string]bool{"kerfuffle": true, "sharbert": true, "fornax": true},
+		substrings: []string{"frick"},
+	}
+	tests := map[string]string{
+		"This is a kerfuffle opinion I need to share with the world": "This is a **** opinion I need to share with the world",
+		"Kerfuffle! fornax, (Sharbert)":                              "****! ****, (****)",
+		"k3rfuffl3 and $harbert and f0rn4x":                          "**** and **** and ****",
+		"kеrfufflе with cyrillic е":                                  "**** with cyrillic е",
+		"ｆｏｒｎａｘ in fullwidth":                                        "**** in fullwidth",
+		"f.o.r.n.a.x is dotted":                                      "**** is dotted",
+		"fricking and unfrickable":                                   "**** and ****",
+		"kerfuffles is not a whole word":                             "kerfuffles is not a whole word",
+		"spacing  is\tkept":                                          "spacing  is\tkept",
+	}
+	for input, want := range tests {
+		if output := filter.clean(input); output != want {
+			t.Errorf(
+				"clean(\"%s\") = (\"%s\"), want (\"%s\")",
+				input, output, want,
+			)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+type profileUpdate struct {
+	Username    *string
+	DisplayName *string
+	Bio         *string
+	Location    *string
+}
+
+type userPatch struct {
+	profileUpdate
+	Email           *string
+	Password        *string
+	CurrentPassword *string
+}
+
+type profileError string
+
+func (e profileError) Error() string {
+	return string(e)
+}
+
+// decodeUserPatch reads a JSON Merge Patch (RFC 7396) document. Absent members
+// leave a field untouched, null clears it and only string values are accepted.
+func decodeUserPatch(reader io.Reader) (userPatch, error) {
+	var patch userPatch
+	var members map[string]json.RawMessage
+	if err := json.NewDecoder(reader).Decode(&members); err != nil {
+		return patch, profileError(errorInvalidPatch)
+	}
+	targets := map[string]**string{
+		"username":         &patch.Username,
+		"display_name":     &patch.DisplayName,
+		"bio":              &patch.Bio,
+		"location":         &patch.Location,
+		"email":            &patch.Email,
+		"password":         &patch.Password,
+		"current_password": &patch.CurrentPassword,
+	}
+	for name, raw := range members {
+		target, ok := targets[name]
+		if !ok {
+			return patch, profileError(errorInvalidPatch)
+		}
+		value := empty
+		if string(raw) != jsonNull && json.Unmarshal(raw, &value) != nil {
+			return patch, profileError(errorInvalidPatch)
+		}
+		*target = &value
+	}
+	if (patch.Email != nil && len(*patch.Email) == 0) ||
+		(patch.Password != nil && len(*patch.Password) == 0) {
+		return patch, profileError(errorInvalidPatch)
+	}
+	return patch, nil
+}
+
+func (c *ApiConfig) getUserFromIdOrUsername(ctx context.Context, idOrUsername string) (database.User, error) {
+	if userId, err := uuid.Parse(idOrUsername); err == nil {
+		return c.DBQueries.GetUserFromId(ctx, userId)
+	}
+	return c.DBQueries.GetUserFromUsername(ctx, nullString(normaliseUsername(idOrUsername)))
+}
+
+func (c *ApiConfig) applyProfileUpdate(ctx context.Context, user database.User,
+	update profileUpdate) (database.UpdateUserProfileParams, error) {
+	params := database.UpdateUserProfileParams{
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Location:    user.Location,
+	}
+	if update.Username != nil {
+		username := normaliseUsername(*update.Username)
+		if len(username) > 0 && !isValidUsername(username) {
+			return params, profileError(errorInvalidUsername)
+		}
+		if owner, err := c.DBQueries.GetUserFromUsername(
+			ctx,
+			nullString(username),
+		); err == nil && owner.ID != user.ID {
+			return params, profileError(errorUsernameTaken)
+		} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return params, err
+		}
+		params.Username = nullString(username)
+	}
+	for _, field := range []struct {
+		value     *string
+		target    *string
+		maxLength int
+		multiline bool
+		message   string
+	}{
+		{update.DisplayName, &params.DisplayName, profileDisplayNameLength, false, errorInvalidDisplayName},
+		{update.Bio, &params.Bio, profileBioLength, true, errorInvalidBio},
+		{update.Location, &params.Location, profileLocationLength, false, errorInvalidLocation},
+	} {
+		if field.value == nil {
+			continue
+		}
+		value := normaliseProfileText(*field.value, field.multiline)
+		if countGraphemes(value) > field.maxLength {
+			return params, profileError(field.message)
+		}
+		*field.target = value
+	}
+	return params, nil
+}
+
+// updateUser saves credentials and profile together. Given a client, every
+// session, access token and personal access token is revoked and a new
+// session is started for it.
+func (c *ApiConfig) updateUser(ctx context.Context, credentials database.UpdateUserCredentialsParams,
+	profile database.UpdateUserProfileParams, client *sessionClient) (database.User, string,
+	database.RefreshToken, error) {
+	var err error
+	var tx *sql.Tx
+	var user database.User
+	var refreshToken string
+	var session database.RefreshToken
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return user, empty, session, err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if _, err = queries.UpdateUserCredentials(ctx, credentials); err != nil {
+		return user, empty, session, err
+	}
+	if client != nil {
+		if err = queries.DeleteRefreshTokensFromUser(
+			ctx,
+			uuid.NullUUID{UUID: credentials.ID, Valid: true},
+		); err != nil {
+			return user, empty, session, err
+		}
+		if err = queries.DeletePersonalAccessTokensFromUser(ctx, credentials.ID); err != nil {
+			return user, empty, session, err
+		}
+		if _, err = queries.UpdateUserTokenVersion(ctx, credentials.ID); err != nil {
+			return user, empty, session, err
+		}
+		if refreshToken, session, err = createRefreshToken(ctx, queries, credentials.ID, *client, oauthGrant{}); err != nil {
+			return user, empty, session, err
+		}
+	}
+	if user, err = queries.UpdateUserProfile(ctx, profile); err != nil {
+		return user, empty, session, err
+	}
+	return user, refreshToken, session, tx.Commit()
+}
+
+func (c *ApiConfig) setUserAvatar(ctx context.Context, userId uuid.UUID, contentType string,
+	image []byte) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.UpsertAvatar(
+		ctx,
+		database.UpsertAvatarParams{
+			UserID:      userId,
+			ContentType: contentType,
+			Image:       image,
+		},
+	); err != nil {
+		return err
+	}
+	if err = queries.UpdateUserAvatar(
+		ctx,
+		database.UpdateUserAvatarParams{
+			ID:              userId,
+			AvatarUpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		},
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *ApiConfig) deleteUserAvatar(ctx context.Context, userId uuid.UUID) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.DeleteAvatar(ctx, userId); err != nil {
+		return err
+	}
+	if err = queries.UpdateUserAvatar(
+		ctx,
+		database.UpdateUserAvatarParams{ID: userId},
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func checkAvatar(body []byte) (string, error) {
+	contentType := http.DetectContentType(body)
+	if !slices.Contains(avatarContentTypes, contentType) {
+		return empty, errors.New(errorInvalidAvatar)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil || config.Width > avatarMaxDimension || config.Height > avatarMaxDimension {
+		return empty, errors.New(errorInvalidAvatar)
+	}
+	return contentType, nil
+}
+
+func avatarUrl(user database.User) string {
+	if !user.AvatarUpdatedAt.Valid {
+		return empty
+	}
+	return fmt.Sprintf(avatarPath, user.ID, user.AvatarUpdatedAt.Time.Unix())
+}
+
+func normaliseUsername(username string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+}
+
+func isValidUsername(username string) bool {
+	return regexUsername.MatchString(username) && !slices.Contains(reservedUsernames, username)
+}
+
+func normaliseProfileText(text string, multiline bool) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && (r != '\n' || !multiline) {
+			return -1
+		}
+		return r
+	}, text))
+}
@@ -0,0 +1,183 @@
+package web
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestIsValidUsername(t *testing.T) {
+	tests := map[string]bool{
+		normaliseUsername("  @Chirper_42 "):    true,
+		"abc":                                  true,
+		"ab":                                   false,
+		"has space":                            false,
+		"has-dash":                             false,
+		"me":                                   false,
+		"admin":                                false,
+		"a23456789012345678901234567890":       true,
+		"a234567890123456789012345678901":      false,
+		"0b4f8e6c-7a4e-4a8e-9c1e-5f7e8c9d0a1b": false,
+	}
+	for input, want := range tests {
+		if output := isValidUsername(input); output != want {
+			t.Errorf(
+				"isValidUsername(\"%s\") = (%t), want (%t)",
+				input, output, want,
+			)
+		}
+	}
+}
+
+func TestNormaliseProfileText(t *testing.T) {
+	testsSingle := map[string]string{
+		"  Ada Lovelace\n":   "Ada Lovelace",
+		"line one\nline two": "line oneline two",
+		"tab\tand\x00null":   "tabandnull",
+	}
+	testsMulti := map[string]string{
+		"line one\nline two": "line one\nline two",
+		"  line one\n\n":     "line one",
+		"tab\tand\x00null":   "tabandnull",
+	}
+	for input, want := range testsSingle {
+		if output := normaliseProfileText(input, false); output != want {
+			t.Errorf(
+				"normaliseProfileText(%q, false) = (%q), want (%q)",
+				input, output, want,
+			)
+		}
+	}
+	for input, want := range testsMulti {
+		if output := normaliseProfileText(input, true); output != want {
+			t.Errorf(
+				"normaliseProfileText(%q, true) = (%q), want (%q)",
+				input, output, want,
+			)
+		}
+	}
+}
+
+func TestCheckAvatar(t *testing.T) {
+	encode := func(size int) []byte {
+		var buffer bytes.Buffer
+		if err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, size, size))); err != nil {
+			t.Fatal(err)
+		}
+		return buffer.Bytes()
+	}
+	inputOk := encode(64)
+	if output, err := checkAvatar(inputOk); err != nil || output != "image/png" {
+		t.Errorf(
+			"checkAvatar(64x64 png) = (\"%s\", %v), want (\"image/png\", nil)",
+			output, err,
+		)
+	}
+	testsErr := map[string][]byte{
+		"large png": encode(avatarMaxDimension + 1),
+		"svg":       []byte("<svg xmlns='http://www.w3.org/2000/svg'/>"),
+	}
+	for input, avatar := range testsErr {
+		if output, err := checkAvatar(avatar); err == nil {
+			t.Errorf(
+				"checkAvatar(%s) = (\"%s\", nil), want (\"\", error)",
+				input, output,
+			)
+		}
+	}
+}
+
+func TestDecodeUserPatch(t *testing.T) {
+	input := `{"bio": null, "location": "Lisbon"}`
+	if output, err := decodeUserPatch(strings.NewReader(input)); err != nil ||
+		output.Bio == nil || *output.Bio != empty ||
+		output.Location == nil || *output.Location != "Lisbon" ||
+		output.Username != nil || output.DisplayName != nil || output.Email != nil || output.Password != nil {
+		t.Errorf(
+			"decodeUserPatch(%s) = (%+v, %v), want (bio cleared and location Lisbon only, nil)",
+			input, output, err,
+		)
+	}
+
+	testsErr := []string{
+		`{"role": "admin"}`,
+		`{"bio": 42}`,
+		`{"email": null}`,
+		`{"password": ""}`,
+		`[]`,
+		`not json`,
+	}
+	for _, input := range testsErr {
+		if output, err := decodeUserPatch(strings.NewReader(input)); err == nil {
+			t.Errorf(
+				"decodeUserPatch(%s) = (%+v, nil), want (error)",
+				input, output,
+			)
+		}
+	}
+}
+
+func TestPutApiUsers(t *testing.T) {
+	now, userId := time.Now(), uuid.New()
+	hash, err := auth.HashPassword("current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := auth.MakeJWT(userId, roleUser, "secret", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := testUserResult(userId)
+	user.rows[0][4] = hash
+	session := testResult{
+		columns: []string{
+			"token_hash", "created_at", "updated_at", "user_id", "expires_at", "revoked_at", "family_id",
+			"rotated_at", "started_at", "last_used_at", "user_agent", "ip_address", "device", "client_id",
+			"scopes",
+		},
+		rows: [][]driver.Value{{
+			"hash", now, now, userId.String(), now, nil, uuid.NewString(),
+			nil, now, now, empty, empty, empty, nil,
+			empty,
+		}},
+	}
+	tests := map[string]int{
+		`{"email": "walt@example.com", "password": "new"}`:                                http.StatusUnauthorized,
+		`{"email": "walt@example.com", "password": "new", "current_password": "wrong"}`:   http.StatusUnauthorized,
+		`{"email": "walt@example.com", "current_password": "current"}`:                    http.StatusBadRequest,
+		`{"email": "walt@example.com", "password": "new", "current_password": "current"}`: http.StatusOK,
+	}
+	for input, want := range tests {
+		db, testDb := newTestDb(map[string]testResult{
+			"GetUserFromId":          user,
+			"UpdateUserCredentials":  user,
+			"UpdateUserTokenVersion": user,
+			"CreateRefreshToken":     session,
+			"UpdateUserProfile":      user,
+		})
+		config := ApiConfig{
+			DB:        db,
+			DBQueries: database.New(db),
+			Keys:      auth.NewKeySet(auth.AlgorithmHs256, "secret", true),
+		}
+		request := httptest.NewRequest(http.MethodPut, "/api/users", strings.NewReader(input))
+		request.Header.Set(headerAuthorization, authorizationBearer+space+token)
+		recorder := httptest.NewRecorder()
+		HandlerPutApiUsers(&config)(recorder, request)
+		if output := recorder.Code; output != want || recorder.Header().Get(headerDeprecation) != deprecationTrue ||
+			slices.Contains(testDb.ran(), "UpdateUserCredentials") != (want == http.StatusOK) {
+			t.Errorf("PUT /api/users %s = (%d) after (%v), want (%d)", input, output, testDb.ran(), want)
+		}
+	}
+}
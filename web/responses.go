@@ -2,9 +2,16 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
 	"github.com/mamatb/Chirpy/database"
 )
 
@@ -58,11 +65,197 @@ func respJsonUser(w http.ResponseWriter, _ *http.Request, user database.User,
 	var err error
 	var body []byte
 	if body, err = json.Marshal(jsonUser{
-		Id:           user.ID,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
-		Email:        user.Email,
-		IsChirpyRed:  user.IsChirpyRed,
+		Id:            user.ID,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		Email:         user.Email,
+		IsChirpyRed:   user.IsChirpyRed,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		MfaEnabled:    user.TotpEnabledAt.Valid,
+		Username:      user.Username.String,
+		DisplayName:   user.DisplayName,
+		Bio:           user.Bio,
+		Location:      user.Location,
+		AvatarUrl:     avatarUrl(user),
+		Token:         token,
+		RefreshToken:  refreshToken,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonProfile(w http.ResponseWriter, _ *http.Request, user database.User) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonProfile{
+		Id:          user.ID,
+		CreatedAt:   user.CreatedAt,
+		Username:    user.Username.String,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Location:    user.Location,
+		AvatarUrl:   avatarUrl(user),
+		IsChirpyRed: user.IsChirpyRed,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respImageAvatar(w http.ResponseWriter, _ *http.Request, avatar database.Avatar) {
+	w.Header().Set(headerContentType, avatar.ContentType)
+	w.Header().Set(headerCacheControl, cacheControlAvatar)
+	if _, err := w.Write(avatar.Image); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonMfaChallenge(w http.ResponseWriter, _ *http.Request, mfaToken string,
+	challenge database.MfaChallenge) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonMfaChallenge{
+		MfaRequired: true,
+		MfaToken:    mfaToken,
+		ExpiresAt:   challenge.ExpiresAt,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonTotpEnrolment(w http.ResponseWriter, _ *http.Request, enrolment totpEnrolment) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonTotpEnrolment{
+		Secret:     enrolment.Secret,
+		OtpauthUri: enrolment.OtpauthUri,
+		QrCode:     enrolment.QrCode,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonRecoveryCodes(w http.ResponseWriter, _ *http.Request, recoveryCodes []string) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonRecoveryCodes{
+		RecoveryCodes: recoveryCodes,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonJwks(w http.ResponseWriter, _ *http.Request, jwks auth.Jwks) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.Header().Set(headerCacheControl, cacheControlJwks)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jwks); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonSessions(w http.ResponseWriter, _ *http.Request, sessions []database.RefreshToken,
+	currentId string) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	sessionsJson := []jsonSession{}
+	for _, session := range sessions {
+		sessionsJson = append(sessionsJson, jsonSession{
+			Id:         session.FamilyID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IpAddress:  session.IpAddress,
+			CreatedAt:  session.StartedAt,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.FamilyID.String() == currentId,
+		})
+	}
+	if body, err = json.Marshal(sessionsJson); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonPersonalAccessTokens(w http.ResponseWriter, _ *http.Request,
+	accessTokens []database.PersonalAccessToken) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	accessTokensJson := []jsonPersonalAccessToken{}
+	for _, accessToken := range accessTokens {
+		accessTokensJson = append(accessTokensJson, newJsonPersonalAccessToken(accessToken, empty))
+	}
+	if body, err = json.Marshal(accessTokensJson); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonPersonalAccessTokenCreated(w http.ResponseWriter, _ *http.Request,
+	accessToken database.PersonalAccessToken, token string) {
+	w.WriteHeader(http.StatusCreated)
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(newJsonPersonalAccessToken(accessToken, token)); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newJsonPersonalAccessToken(accessToken database.PersonalAccessToken, token string) jsonPersonalAccessToken {
+	accessTokenJson := jsonPersonalAccessToken{
+		Id:        accessToken.ID,
+		CreatedAt: accessToken.CreatedAt,
+		Name:      accessToken.Name,
+		Scopes:    strings.Fields(accessToken.Scopes),
+		Token:     token,
+	}
+	if accessToken.ExpiresAt.Valid {
+		accessTokenJson.ExpiresAt = &accessToken.ExpiresAt.Time
+	}
+	if accessToken.LastUsedAt.Valid {
+		accessTokenJson.LastUsedAt = &accessToken.LastUsedAt.Time
+	}
+	return accessTokenJson
+}
+
+func respJsonToken(w http.ResponseWriter, _ *http.Request, token string, refreshToken string) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonToken{
 		Token:        token,
 		RefreshToken: refreshToken,
 	}); err != nil {
@@ -73,12 +266,59 @@ func respJsonUser(w http.ResponseWriter, _ *http.Request, user database.User,
 	}
 }
 
-func respJsonToken(w http.ResponseWriter, _ *http.Request, token string) {
+func respJsonOauthClients(w http.ResponseWriter, _ *http.Request, clients []database.OauthClient) {
 	w.Header().Set(headerContentType, contentTypeJson)
 	var err error
 	var body []byte
-	if body, err = json.Marshal(jsonToken{
-		Token: token,
+	clientsJson := []jsonOauthClient{}
+	for _, client := range clients {
+		clientsJson = append(clientsJson, newJsonOauthClient(client, empty))
+	}
+	if body, err = json.Marshal(clientsJson); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonOauthClientCreated(w http.ResponseWriter, _ *http.Request, client database.OauthClient,
+	secret string) {
+	w.WriteHeader(http.StatusCreated)
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(newJsonOauthClient(client, secret)); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func newJsonOauthClient(client database.OauthClient, secret string) jsonOauthClient {
+	return jsonOauthClient{
+		Id:           client.ID,
+		CreatedAt:    client.CreatedAt,
+		Name:         client.Name,
+		RedirectUris: strings.Fields(client.RedirectUris),
+		Confidential: client.SecretHash.Valid,
+		ClientSecret: secret,
+	}
+}
+
+func respJsonOauthToken(w http.ResponseWriter, _ *http.Request, token string, refreshToken string,
+	scope string) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonOauthToken{
+		AccessToken:  token,
+		TokenType:    oauthTokenTypeBearer,
+		ExpiresIn:    int(accessTokenExpiry.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scope,
 	}); err != nil {
 		log.Fatal(err)
 	}
@@ -87,7 +327,65 @@ func respJsonToken(w http.ResponseWriter, _ *http.Request, token string) {
 	}
 }
 
-func respJsonChirp(w http.ResponseWriter, _ *http.Request, chirp database.Chirp) {
+func respJsonOauthIntrospection(w http.ResponseWriter, _ *http.Request, introspection jsonOauthIntrospection) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(introspection); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// respJsonOauthError answers the token endpoints the way RFC 6749 asks, with
+// the headers set before the status so clients get to see them.
+func respJsonOauthError(w http.ResponseWriter, _ *http.Request, status int, oauthErr oauthError) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set(headerWwwAuthenticate, wwwAuthenticateBasic)
+	}
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
+	w.WriteHeader(status)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonOauthError{
+		Error:            oauthErr.Code,
+		ErrorDescription: oauthErr.Description,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// respRedirectOauth sends the user back to the client with the outcome of
+// an authorization request and the state the client passed along.
+func respRedirectOauth(w http.ResponseWriter, r *http.Request, authorization oauthAuthorization,
+	params url.Values) {
+	params.Set(oauthParamState, authorization.State)
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
+	http.Redirect(w, r, oauthRedirect(authorization.RedirectUri, params), http.StatusFound)
+}
+
+// respHtmlOauthAuthorize renders the consent screen, which no other site may
+// frame to trick users into approving.
+func respHtmlOauthAuthorize(w http.ResponseWriter, _ *http.Request, status int, page oauthAuthorizePage) {
+	w.Header().Set(headerContentType, contentTypeHtml)
+	w.Header().Set(headerCacheControl, cacheControlNoStore)
+	w.Header().Set(headerFrameOptions, frameOptionsDeny)
+	w.Header().Set(headerContentSecurityPolicy, cspFrameAncestorsNone)
+	w.WriteHeader(status)
+	if err := oauthAuthorizeTemplate.Execute(w, page); err != nil {
+		log.Print(err)
+	}
+}
+
+func respJsonChirp(w http.ResponseWriter, _ *http.Request, chirp database.Chirp,
+	links []jsonLink) {
 	w.Header().Set(headerContentType, contentTypeJson)
 	var err error
 	var body []byte
@@ -97,6 +395,8 @@ func respJsonChirp(w http.ResponseWriter, _ *http.Request, chirp database.Chirp)
 		UpdatedAt: chirp.UpdatedAt,
 		Body:      chirp.Body,
 		UserId:    chirp.UserID,
+		Status:    chirp.Status,
+		Links:     links,
 	}); err != nil {
 		log.Fatal(err)
 	}
@@ -105,7 +405,8 @@ func respJsonChirp(w http.ResponseWriter, _ *http.Request, chirp database.Chirp)
 	}
 }
 
-func respJsonChirps(w http.ResponseWriter, _ *http.Request, chirps []database.Chirp) {
+func respJsonChirps(w http.ResponseWriter, _ *http.Request, chirps []database.Chirp,
+	links map[uuid.UUID][]jsonLink) {
 	w.Header().Set(headerContentType, contentTypeJson)
 	var err error
 	var body []byte
@@ -117,6 +418,8 @@ func respJsonChirps(w http.ResponseWriter, _ *http.Request, chirps []database.Ch
 			UpdatedAt: chirp.UpdatedAt,
 			Body:      chirp.Body,
 			UserId:    chirp.UserID,
+			Status:    chirp.Status,
+			Links:     append([]jsonLink{}, links[chirp.ID]...),
 		})
 	}
 	if body, err = json.Marshal(chirpsJson); err != nil {
@@ -127,14 +430,231 @@ func respJsonChirps(w http.ResponseWriter, _ *http.Request, chirps []database.Ch
 	}
 }
 
+func respJsonProfanity(w http.ResponseWriter, _ *http.Request, profanity database.Profanity) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonProfanity{
+		Word:      profanity.Word,
+		CreatedAt: profanity.CreatedAt,
+		UpdatedAt: profanity.UpdatedAt,
+		Mode:      profanity.Mode,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonProfanities(w http.ResponseWriter, _ *http.Request, profanities []database.Profanity) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	var profanitiesJson []jsonProfanity
+	for _, profanity := range profanities {
+		profanitiesJson = append(profanitiesJson, jsonProfanity{
+			Word:      profanity.Word,
+			CreatedAt: profanity.CreatedAt,
+			UpdatedAt: profanity.UpdatedAt,
+			Mode:      profanity.Mode,
+		})
+	}
+	if body, err = json.Marshal(profanitiesJson); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonReport(w http.ResponseWriter, _ *http.Request, report database.Report) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonReport{
+		Id:         report.ID,
+		CreatedAt:  report.CreatedAt,
+		UpdatedAt:  report.UpdatedAt,
+		ChirpId:    report.ChirpID,
+		ReporterId: report.ReporterID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     report.Status,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonReports(w http.ResponseWriter, _ *http.Request, reports []database.Report) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	var reportsJson []jsonReport
+	for _, report := range reports {
+		reportsJson = append(reportsJson, jsonReport{
+			Id:         report.ID,
+			CreatedAt:  report.CreatedAt,
+			UpdatedAt:  report.UpdatedAt,
+			ChirpId:    report.ChirpID,
+			ReporterId: report.ReporterID,
+			Reason:     report.Reason,
+			Details:    report.Details,
+			Status:     report.Status,
+		})
+	}
+	if body, err = json.Marshal(reportsJson); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonModerationDecision(w http.ResponseWriter, _ *http.Request,
+	decision database.ModerationDecision) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonModerationDecision{
+		Id:          decision.ID,
+		CreatedAt:   decision.CreatedAt,
+		ReportId:    decision.ReportID,
+		ChirpId:     decision.ChirpID,
+		AuthorId:    decision.AuthorID,
+		ModeratorId: decision.ModeratorID,
+		Action:      decision.Action,
+		Note:        decision.Note,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonModerationDecisions(w http.ResponseWriter, _ *http.Request,
+	decisions []database.ModerationDecision) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	var decisionsJson []jsonModerationDecision
+	for _, decision := range decisions {
+		decisionsJson = append(decisionsJson, jsonModerationDecision{
+			Id:          decision.ID,
+			CreatedAt:   decision.CreatedAt,
+			ReportId:    decision.ReportID,
+			ChirpId:     decision.ChirpID,
+			AuthorId:    decision.AuthorID,
+			ModeratorId: decision.ModeratorID,
+			Action:      decision.Action,
+			Note:        decision.Note,
+		})
+	}
+	if body, err = json.Marshal(decisionsJson); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonErasureReceipt(w http.ResponseWriter, _ *http.Request, receipt database.ErasureReceipt) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonErasureReceipt{
+		Id:           receipt.ID,
+		CreatedAt:    receipt.CreatedAt,
+		UserId:       receipt.UserID,
+		EmailHash:    receipt.EmailHash,
+		Policy:       receipt.Policy,
+		ChirpsErased: receipt.ChirpsErased,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonDataExport(w http.ResponseWriter, _ *http.Request, export database.DataExport,
+	downloadUrl string) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonDataExport{
+		Id:          export.ID,
+		CreatedAt:   export.CreatedAt,
+		UpdatedAt:   export.UpdatedAt,
+		Status:      export.Status,
+		ExpiresAt:   export.ExpiresAt,
+		DownloadUrl: downloadUrl,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonImportResult(w http.ResponseWriter, _ *http.Request, result ImportResult) {
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(result); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respZipDataExport(w http.ResponseWriter, _ *http.Request, export database.DataExport) {
+	w.Header().Set(headerContentType, contentTypeZip)
+	w.Header().Set(headerContentDisposition, fmt.Sprintf(exportFileName, export.ID))
+	if _, err := w.Write(export.Archive); err != nil {
+		log.Fatal(err)
+	}
+}
+
 func respJsonUserCreated(w http.ResponseWriter, r *http.Request, user database.User) {
 	w.WriteHeader(http.StatusCreated)
 	respJsonUser(w, r, user, empty, empty)
 }
 
-func respJsonChirpCreated(w http.ResponseWriter, r *http.Request, chirp database.Chirp) {
+func respJsonChirpCreated(w http.ResponseWriter, r *http.Request, chirp database.Chirp,
+	links []jsonLink) {
+	if chirp.Status == chirpStatusModeration {
+		w.WriteHeader(http.StatusAccepted)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	respJsonChirp(w, r, chirp, links)
+}
+
+func respJsonProfanityCreated(w http.ResponseWriter, r *http.Request, profanity database.Profanity) {
+	w.WriteHeader(http.StatusCreated)
+	respJsonProfanity(w, r, profanity)
+}
+
+func respJsonReportCreated(w http.ResponseWriter, r *http.Request, report database.Report) {
+	w.WriteHeader(http.StatusCreated)
+	respJsonReport(w, r, report)
+}
+
+func respJsonModerationDecisionCreated(w http.ResponseWriter, r *http.Request,
+	decision database.ModerationDecision) {
 	w.WriteHeader(http.StatusCreated)
-	respJsonChirp(w, r, chirp)
+	respJsonModerationDecision(w, r, decision)
+}
+
+func respJsonDataExportAccepted(w http.ResponseWriter, r *http.Request, export database.DataExport) {
+	w.WriteHeader(http.StatusAccepted)
+	respJsonDataExport(w, r, export, empty)
 }
 
 func respJsonBadRequest(w http.ResponseWriter, _ *http.Request, message string) {
@@ -166,3 +686,66 @@ func respJsonUnauthorized(w http.ResponseWriter, _ *http.Request, message string
 		log.Fatal(err)
 	}
 }
+
+func respJsonConflict(w http.ResponseWriter, _ *http.Request, message string) {
+	w.WriteHeader(http.StatusConflict)
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonError{
+		Error: message,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonTooManyRequests(w http.ResponseWriter, _ *http.Request, message string,
+	retryAfter time.Duration) {
+	w.Header().Set(headerRetryAfter, strconv.Itoa(int(retryAfter.Seconds())))
+	w.Header().Set(headerContentType, contentTypeJson)
+	w.WriteHeader(http.StatusTooManyRequests)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonError{
+		Error: message,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonChirpRejected(w http.ResponseWriter, _ *http.Request, rejections ChirpRejections) {
+	w.WriteHeader(http.StatusBadRequest)
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonErrorChirpRejected{
+		Error:   rejections.Error(),
+		Reasons: rejections,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func respJsonForbidden(w http.ResponseWriter, _ *http.Request, message string) {
+	w.WriteHeader(http.StatusForbidden)
+	w.Header().Set(headerContentType, contentTypeJson)
+	var err error
+	var body []byte
+	if body, err = json.Marshal(jsonError{
+		Error: message,
+	}); err != nil {
+		log.Fatal(err)
+	}
+	if _, err = w.Write(body); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+var errSessionNotFound = errors.New(errorSessionNotFound)
+
+// sessionClient describes where a session was started or last refreshed
+// from, so users can tell their sessions apart.
+type sessionClient struct {
+	UserAgent string
+	IpAddress string
+	Device    string
+}
+
+func newSessionClient(r *http.Request) sessionClient {
+	userAgent := r.UserAgent()
+	if len(userAgent) > sessionUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:sessionUserAgentLength], empty)
+	}
+	return sessionClient{
+		UserAgent: userAgent,
+		IpAddress: clientIp(r),
+		Device:    deviceFromUserAgent(userAgent),
+	}
+}
+
+// TokenVersion is the version access tokens of the user must carry, as long
+// as the session they were handed out for has not ended. Tokens without a
+// session predate sessions and only have their version checked.
+func (c *ApiConfig) TokenVersion(ctx context.Context, userId uuid.UUID, sessionId string) (int32, error) {
+	if len(sessionId) == 0 {
+		return c.DBQueries.GetUserTokenVersion(ctx, userId)
+	}
+	familyId, err := uuid.Parse(sessionId)
+	if err != nil {
+		return 0, err
+	}
+	return c.DBQueries.GetUserTokenVersionFromSession(
+		ctx,
+		database.GetUserTokenVersionFromSessionParams{
+			ID:       userId,
+			FamilyID: familyId,
+		},
+	)
+}
+
+// logOutAll ends every session and personal access token of a user and, by
+// bumping the token version, every access token already handed out.
+func (c *ApiConfig) logOutAll(ctx context.Context, userId uuid.UUID) error {
+	var err error
+	var tx *sql.Tx
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if err = queries.DeleteRefreshTokensFromUser(
+		ctx,
+		uuid.NullUUID{UUID: userId, Valid: true},
+	); err != nil {
+		return err
+	}
+	if err = queries.DeletePersonalAccessTokensFromUser(ctx, userId); err != nil {
+		return err
+	}
+	if _, err = queries.UpdateUserTokenVersion(ctx, userId); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *ApiConfig) deleteSession(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID) error {
+	rows, err := c.DBQueries.DeleteSession(
+		ctx,
+		database.DeleteSessionParams{
+			FamilyID: sessionId,
+			UserID:   uuid.NullUUID{UUID: userId, Valid: true},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errSessionNotFound
+	}
+	return nil
+}
+
+// deviceFromUserAgent gives a rough name for the device behind a user agent,
+// checking the more specific platforms first.
+func deviceFromUserAgent(userAgent string) string {
+	for _, device := range sessionDevices {
+		if strings.Contains(userAgent, device.marker) {
+			return device.name
+		}
+	}
+	return sessionDeviceUnknown
+}
+
+func clientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
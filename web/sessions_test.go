@@ -0,0 +1,78 @@
+package web
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func TestDeviceFromUserAgent(t *testing.T) {
+	tests := map[string]string{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15":    "iPhone",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0":       "Android",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0":      "Windows",
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Safari/605.1": "Mac",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0":         "Linux",
+		"curl/8.5.0": sessionDeviceUnknown,
+	}
+	for input, want := range tests {
+		if output := deviceFromUserAgent(input); output != want {
+			t.Errorf("deviceFromUserAgent(%q) = %q, want %q", input, output, want)
+		}
+	}
+}
+
+func TestNewSessionClient(t *testing.T) {
+	request := httptest.NewRequest("POST", "/api/login", nil)
+	request.RemoteAddr = "[2001:db8::1]:4321"
+	request.Header.Set(headerUserAgent, "Android "+strings.Repeat("x", 2*sessionUserAgentLength))
+	client := newSessionClient(request)
+	if client.IpAddress != "2001:db8::1" {
+		t.Errorf("newSessionClient() ip = %q, want 2001:db8::1", client.IpAddress)
+	}
+	if len(client.UserAgent) != sessionUserAgentLength || client.Device != "Android" {
+		t.Errorf("newSessionClient() = %d byte user agent on %q", len(client.UserAgent), client.Device)
+	}
+}
+
+func TestTokenVersion(t *testing.T) {
+	version := testResult{columns: []string{"token_version"}, rows: [][]driver.Value{{int64(1)}}}
+	tests := map[string]string{
+		empty:              "GetUserTokenVersion",
+		uuid.NewString():   "GetUserTokenVersionFromSession",
+		"not a session id": empty,
+	}
+	for input, want := range tests {
+		db, testDb := newTestDb(map[string]testResult{
+			"GetUserTokenVersion":            version,
+			"GetUserTokenVersionFromSession": version,
+		})
+		config := ApiConfig{DB: db, DBQueries: database.New(db)}
+		output, err := config.TokenVersion(context.Background(), uuid.New(), input)
+		if ran := strings.Join(testDb.ran(), empty); ran != want || (err == nil) != (want != empty) ||
+			(err == nil && output != 1) {
+			t.Errorf("TokenVersion(%q) = (%d, %v) after (%s), want after (%s)", input, output, err, ran, want)
+		}
+	}
+	db, _ := newTestDb(map[string]testResult{})
+	config := ApiConfig{DB: db, DBQueries: database.New(db)}
+	if _, err := config.TokenVersion(context.Background(), uuid.New(), uuid.NewString()); err == nil {
+		t.Errorf("TokenVersion() of an ended session = (%v), want an error", err)
+	}
+}
+
+func TestLogOutAll(t *testing.T) {
+	db, testDb := newTestDb(map[string]testResult{"UpdateUserTokenVersion": testUserResult(uuid.New())})
+	config := ApiConfig{DB: db, DBQueries: database.New(db)}
+	want := []string{"DeleteRefreshTokensFromUser", "DeletePersonalAccessTokensFromUser", "UpdateUserTokenVersion"}
+	if err := config.logOutAll(context.Background(), uuid.New()); err != nil || !slices.Equal(testDb.ran(), want) ||
+		!testDb.committed {
+		t.Errorf("logOutAll() = (%v, committed %t) after (%v), want after (%v)", err, testDb.committed, testDb.ran(), want)
+	}
+}
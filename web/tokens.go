@@ -0,0 +1,252 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+var (
+	errInsufficientScope           = errors.New(errorInsufficientScope)
+	errPersonalAccessTokenNotFound = errors.New(errorTokenNotFound)
+	errRefreshTokenReused          = errors.New(errorRefreshTokenReused)
+)
+
+// oauthGrant restricts a session to what a third-party client was granted;
+// the zero value is a first-party session with full access.
+type oauthGrant struct {
+	ClientId uuid.NullUUID
+	Scopes   string
+}
+
+// createRefreshToken starts a new family of refresh tokens, one per login,
+// and only ever stores the hash of the token handed to the client.
+func createRefreshToken(ctx context.Context, queries *database.Queries, userId uuid.UUID,
+	client sessionClient, grant oauthGrant) (string, database.RefreshToken, error) {
+	var err error
+	var refreshToken string
+	var session database.RefreshToken
+	if refreshToken, err = auth.MakeRefreshToken(); err != nil {
+		return empty, session, err
+	}
+	if session, err = queries.CreateRefreshToken(
+		ctx,
+		database.CreateRefreshTokenParams{
+			TokenHash: auth.HashToken(refreshToken),
+			UserID:    uuid.NullUUID{UUID: userId, Valid: true},
+			ExpiresAt: time.Now().Add(refreshTokenExpiry),
+			FamilyID:  uuid.New(),
+			StartedAt: time.Now(),
+			UserAgent: client.UserAgent,
+			IpAddress: client.IpAddress,
+			Device:    client.Device,
+			ClientID:  grant.ClientId,
+			Scopes:    grant.Scopes,
+		},
+	); err != nil {
+		return empty, session, err
+	}
+	return refreshToken, session, nil
+}
+
+// rotateRefreshToken swaps a refresh token for the next one in its family,
+// which keeps the family's expiry and grant. Presenting a token that was
+// already rotated means it leaked, so the whole family is revoked. Tokens
+// only rotate for the client they were issued to.
+func (c *ApiConfig) rotateRefreshToken(ctx context.Context, refreshToken string,
+	client sessionClient, clientId uuid.NullUUID) (database.RefreshToken, string, error) {
+	var err error
+	var tx *sql.Tx
+	var current, session database.RefreshToken
+	var next string
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return session, empty, err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if current, err = queries.GetRefreshTokenFromHash(ctx, auth.HashToken(refreshToken)); err != nil {
+		return session, empty, err
+	}
+	if current.RotatedAt.Valid {
+		if err = queries.DeleteRefreshTokensFromFamily(ctx, current.FamilyID); err != nil {
+			return session, empty, err
+		}
+		if err = tx.Commit(); err != nil {
+			return session, empty, err
+		}
+		return session, empty, errRefreshTokenReused
+	}
+	if current.RevokedAt.Valid || !current.UserID.Valid || !time.Now().Before(current.ExpiresAt) ||
+		current.ClientID != clientId {
+		return session, empty, errors.New(errorInvalidRefreshToken)
+	}
+	if err = queries.UpdateRefreshTokenRotated(ctx, current.TokenHash); err != nil {
+		return session, empty, err
+	}
+	if next, err = auth.MakeRefreshToken(); err != nil {
+		return session, empty, err
+	}
+	if session, err = queries.CreateRefreshToken(
+		ctx,
+		database.CreateRefreshTokenParams{
+			TokenHash: auth.HashToken(next),
+			UserID:    current.UserID,
+			ExpiresAt: current.ExpiresAt,
+			FamilyID:  current.FamilyID,
+			StartedAt: current.StartedAt,
+			UserAgent: client.UserAgent,
+			IpAddress: client.IpAddress,
+			Device:    client.Device,
+			ClientID:  current.ClientID,
+			Scopes:    current.Scopes,
+		},
+	); err != nil {
+		return session, empty, err
+	}
+	return session, next, tx.Commit()
+}
+
+// makeAccessToken signs a short lived JWT for a session, carrying the scopes
+// of its grant when it belongs to a third-party client.
+func (c *ApiConfig) makeAccessToken(user database.User, session database.RefreshToken) (string, error) {
+	claims := auth.Claims{
+		Role:         user.Role,
+		TokenVersion: user.TokenVersion,
+		SessionId:    session.FamilyID.String(),
+		Scope:        session.Scopes,
+	}
+	if session.ClientID.Valid {
+		claims.ClientId = session.ClientID.UUID.String()
+	}
+	return c.Keys.MakeJWT(user.ID, claims, accessTokenExpiry)
+}
+
+// validateToken accepts a session JWT, which may do anything its user can,
+// or a personal access token or third-party JWT holding scope. An empty
+// scope is for account and security endpoints, which only a logged in
+// session may use.
+func (c *ApiConfig) validateToken(ctx context.Context, token string, scope string) (uuid.UUID, error) {
+	var err error
+	var claims auth.Claims
+	var accessToken database.PersonalAccessToken
+	if !auth.IsPersonalAccessToken(token) {
+		if claims, err = c.Keys.ValidateJWTClaims(token); err != nil {
+			return uuid.Nil, err
+		}
+		if len(claims.ClientId) > 0 && !hasScope(claims.Scope, scope) {
+			return uuid.Nil, errInsufficientScope
+		}
+		return uuid.MustParse(claims.Subject), nil
+	}
+	if accessToken, err = c.DBQueries.GetPersonalAccessTokenFromHash(ctx, auth.HashToken(token)); err != nil {
+		return uuid.Nil, err
+	}
+	if !hasScope(accessToken.Scopes, scope) {
+		return uuid.Nil, errInsufficientScope
+	}
+	if err = c.DBQueries.UpdatePersonalAccessTokenUsed(ctx, accessToken.ID); err != nil {
+		return uuid.Nil, err
+	}
+	return accessToken.UserID, nil
+}
+
+// tokenError is a personal access token request the user can fix, as opposed
+// to a failure on our side.
+type tokenError string
+
+func (e tokenError) Error() string {
+	return string(e)
+}
+
+// canReadChirps keeps chirps public, but a personal access token sent along
+// has to be live and allowed to read them, so a revoked bot notices.
+func (c *ApiConfig) canReadChirps(r *http.Request) bool {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil || !auth.IsPersonalAccessToken(token) {
+		return true
+	}
+	_, err = c.validateToken(r.Context(), token, scopeChirpsRead)
+	return err == nil
+}
+
+func (c *ApiConfig) createPersonalAccessToken(ctx context.Context, userId uuid.UUID, name string,
+	scopes []string, expiresInDays int) (database.PersonalAccessToken, string, error) {
+	var err error
+	var token, scopesField string
+	var accessToken database.PersonalAccessToken
+	name = strings.TrimSpace(name)
+	if len(name) == 0 || countGraphemes(name) > tokenNameLength {
+		return accessToken, empty, tokenError(errorInvalidTokenName)
+	}
+	if scopesField, err = normaliseScopes(scopes); err != nil {
+		return accessToken, empty, err
+	}
+	if expiresInDays < 0 || expiresInDays > tokenMaxExpiryDays {
+		return accessToken, empty, tokenError(errorInvalidTokenExpiry)
+	}
+	expiresAt := sql.NullTime{}
+	if expiresInDays > 0 {
+		expiresAt = sql.NullTime{Time: time.Now().AddDate(0, 0, expiresInDays), Valid: true}
+	}
+	if token, err = auth.MakePersonalAccessToken(); err != nil {
+		return accessToken, empty, err
+	}
+	if accessToken, err = c.DBQueries.CreatePersonalAccessToken(
+		ctx,
+		database.CreatePersonalAccessTokenParams{
+			UserID:    userId,
+			Name:      name,
+			TokenHash: auth.HashToken(token),
+			Scopes:    scopesField,
+			ExpiresAt: expiresAt,
+		},
+	); err != nil {
+		return accessToken, empty, err
+	}
+	return accessToken, token, nil
+}
+
+func (c *ApiConfig) deletePersonalAccessToken(ctx context.Context, userId uuid.UUID, tokenId uuid.UUID) error {
+	rows, err := c.DBQueries.DeletePersonalAccessToken(
+		ctx,
+		database.DeletePersonalAccessTokenParams{
+			ID:     tokenId,
+			UserID: userId,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errPersonalAccessTokenNotFound
+	}
+	return nil
+}
+
+func hasScope(scopes string, scope string) bool {
+	return len(scope) > 0 && slices.Contains(strings.Fields(scopes), scope)
+}
+
+// normaliseScopes stores scopes space separated, sorted and without repeats,
+// the way OAuth writes them.
+func normaliseScopes(scopes []string) (string, error) {
+	if len(scopes) == 0 {
+		return empty, tokenError(errorInvalidScopes)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(personalAccessTokenScopes, scope) {
+			return empty, tokenError(errorInvalidScopes)
+		}
+	}
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	return strings.Join(slices.Compact(scopes), space), nil
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormaliseScopes(t *testing.T) {
+	testsOk := map[string]string{
+		scopeChirpsWrite: "chirps:write",
+		scopeProfileWrite + space + scopeChirpsRead + space + scopeChirpsRead: "chirps:read profile:write",
+	}
+	testsErr := []string{
+		empty,
+		scopeChirpsRead + space + "admin",
+	}
+	for input, want := range testsOk {
+		if output, err := normaliseScopes(strings.Fields(input)); output != want || err != nil {
+			t.Errorf("normaliseScopes(\"%s\") = (%s, %v), want (%s, nil)", input, output, err, want)
+		}
+	}
+	for _, input := range testsErr {
+		var invalid tokenError
+		if _, err := normaliseScopes(strings.Fields(input)); !errors.As(err, &invalid) {
+			t.Errorf("normaliseScopes(\"%s\") = (%v), want (tokenError)", input, err)
+		}
+	}
+}
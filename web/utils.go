@@ -1,13 +1,78 @@
 package web
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
-func cleanProfanities(body string, profanities map[string]bool) string {
-	bodySlice := strings.Split(body, space)
-	for wordIdx, word := range bodySlice {
-		if profanities[strings.ToLower(word)] {
-			bodySlice[wordIdx] = profanityReplacement
+func chirpLength(body string) int {
+	length, previous := 0, 0
+	for _, index := range findUrls(body) {
+		length += countGraphemes(body[previous:index[0]]) + chirpUrlLength
+		previous = index[1]
+	}
+	return length + countGraphemes(body[previous:])
+}
+
+// findUrls locates the urls in a chirp, leaving out the punctuation that
+// ends a sentence and closing brackets that were not opened in the url.
+func findUrls(body string) [][]int {
+	indexes := regexUrl.FindAllStringIndex(body, -1)
+	for _, index := range indexes {
+		index[1] = index[0] + len(trimUrl(body[index[0]:index[1]]))
+	}
+	return indexes
+}
+
+func findUrlStrings(body string) []string {
+	var urls []string
+	for _, index := range findUrls(body) {
+		urls = append(urls, body[index[0]:index[1]])
+	}
+	return urls
+}
+
+func trimUrl(url string) string {
+	for len(url) > 0 {
+		switch last := url[len(url)-1]; {
+		case strings.IndexByte(urlTrailingPunctuation, last) >= 0:
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+		case last == ']' && strings.Count(url, "[") < strings.Count(url, "]"):
+		default:
+			return url
 		}
+		url = url[:len(url)-1]
 	}
-	return strings.Join(bodySlice, space)
+	return url
+}
+
+func countGraphemes(text string) int {
+	count, regionals, previous := 0, 0, rune(-1)
+	for _, r := range text {
+		switch {
+		case previous == '\r' && r == '\n':
+		case previous == zeroWidthJoiner || r == zeroWidthJoiner || isGraphemeExtend(r):
+		case isRegionalIndicator(r) && regionals%2 == 1:
+		default:
+			count++
+		}
+		if isRegionalIndicator(r) {
+			regionals++
+		} else {
+			regionals = 0
+		}
+		previous = r
+	}
+	return count
+}
+
+func isGraphemeExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector) ||
+		(r >= 0x1160 && r <= 0x11ff) || // hangul medial vowels and final consonants
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // emoji skin tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) // emoji tag sequences
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
 }
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChirpLength(t *testing.T) {
+	tests := map[string]int{
+		"":                              0,
+		"hello":                         5,
+		"café":                          4,
+		"cafe\u0301":                    4,
+		"🐦🐦🐦":                           3,
+		"👍🏽":                            1,
+		"👩\u200d👩\u200d👧":               1,
+		"🇪🇸🇫🇷":                          2,
+		"see https://example.com/a/b/c": 4 + chirpUrlLength,
+		"see https://example.com/a.":    5 + chirpUrlLength,
+		strings.Repeat("é", 140):        140,
+	}
+	for input, want := range tests {
+		if output := chirpLength(input); output != want {
+			t.Errorf(
+				"chirpLength(\"%s\") = (%d), want (%d)",
+				input, output, want,
+			)
+		}
+	}
+}
+
+func TestFindUrlStrings(t *testing.T) {
+	tests := map[string]string{
+		"see https://example.com/a.":                   "https://example.com/a",
+		"(see https://example.com/a), then":            "https://example.com/a",
+		"is it https://example.com/a?!":                "https://example.com/a",
+		"\"https://example.com/a?b=c\"":                "https://example.com/a?b=c",
+		"https://en.wikipedia.org/wiki/Go_(language).": "https://en.wikipedia.org/wiki/Go_(language)",
+		"[https://example.com/a]":                      "https://example.com/a",
+		"https://example.com/a#b":                      "https://example.com/a#b",
+	}
+	for input, want := range tests {
+		if output := findUrlStrings(input); len(output) != 1 || output[0] != want {
+			t.Errorf(
+				"findUrlStrings(\"%s\") = (%q), want ([\"%s\"])",
+				input, output, want,
+			)
+		}
+	}
+}
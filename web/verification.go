@@ -0,0 +1,88 @@
+package web
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/mamatb/Chirpy/auth"
+	"github.com/mamatb/Chirpy/database"
+)
+
+func (c *ApiConfig) sendEmailVerification(ctx context.Context, user database.User) error {
+	var err error
+	var token string
+	if token, err = auth.MakeToken(); err != nil {
+		return err
+	}
+	if _, err = c.DBQueries.CreateEmailVerification(
+		ctx,
+		database.CreateEmailVerificationParams{
+			UserID:    user.ID,
+			Email:     user.Email,
+			TokenHash: auth.HashToken(token),
+			ExpiresAt: time.Now().Add(verificationExpiry),
+		},
+	); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set(queryToken, token)
+	return c.Mailer.Send(ctx, Mail{
+		To:      user.Email,
+		Subject: mailVerificationSubject,
+		Body:    fmt.Sprintf(mailVerificationBody, c.BaseUrl+verifyPath+"?"+query.Encode()),
+	})
+}
+
+// sendEmailVerificationAsync keeps slow mail servers out of the request path,
+// failures are logged and the user can ask for a new link
+func (c *ApiConfig) sendEmailVerificationAsync(user database.User) {
+	go func() {
+		if err := c.sendEmailVerification(context.Background(), user); err != nil {
+			log.Print(err)
+		}
+	}()
+}
+
+func (c *ApiConfig) verifyEmail(ctx context.Context, token string) error {
+	var err error
+	var tx *sql.Tx
+	var verification database.EmailVerification
+	var user database.User
+	if tx, err = c.DB.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	queries := c.DBQueries.WithTx(tx)
+	if verification, err = queries.GetEmailVerificationFromHash(ctx, auth.HashToken(token)); err != nil {
+		return err
+	}
+	if user, err = queries.GetUserFromId(ctx, verification.UserID); err != nil {
+		return err
+	}
+	if user.Email != verification.Email {
+		return errors.New(errorInvalidToken)
+	}
+	if err = queries.UpdateEmailVerificationUsed(ctx, verification.ID); err != nil {
+		return err
+	}
+	if err = queries.UpdateUserEmailVerified(
+		ctx,
+		database.UpdateUserEmailVerifiedParams{
+			ID:    user.ID,
+			Email: user.Email,
+		},
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *ApiConfig) canChirp(user database.User) bool {
+	return !c.RequireEmailVerification || user.EmailVerifiedAt.Valid
+}